@@ -0,0 +1,82 @@
+package hapi
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheDirective is a directive of the Cache-Control header, see WithCacheControl
+type CacheDirective string
+
+const (
+	// CachePublic lets shared caches, like CDNs, store the response
+	CachePublic CacheDirective = "public"
+
+	// CachePrivate only lets the client's own cache store the response
+	CachePrivate CacheDirective = "private"
+
+	// CacheNoStore stops every cache from storing the response
+	CacheNoStore CacheDirective = "no-store"
+)
+
+// CacheMaxAge is how long the response is fresh for
+func CacheMaxAge(age time.Duration) CacheDirective {
+	return CacheDirective("max-age=" + cacheSeconds(age))
+}
+
+// CacheSharedMaxAge is how long the response is fresh for in shared caches, it wins over CacheMaxAge there
+func CacheSharedMaxAge(age time.Duration) CacheDirective {
+	return CacheDirective("s-maxage=" + cacheSeconds(age))
+}
+
+// CacheStaleWhileRevalidate is how long a stale response can still be used while it is fetched again in the background
+func CacheStaleWhileRevalidate(stale time.Duration) CacheDirective {
+	return CacheDirective("stale-while-revalidate=" + cacheSeconds(stale))
+}
+
+// setCacheHeaders sets the Cache-Control and Vary headers from WithCacheControl and WithVary
+func setCacheHeaders(w http.ResponseWriter, o responseOptions) {
+	if o.cacheControl != nil {
+		directives := make([]string, 0, len(o.cacheControl))
+		for _, directive := range o.cacheControl {
+			directives = append(directives, string(directive))
+		}
+
+		w.Header().Set("Cache-Control", strings.Join(directives, ", "))
+	}
+
+	addVary(w.Header(), o.vary...)
+}
+
+// addVary adds headers to the Vary header unless they are already in it
+func addVary(header http.Header, headers ...string) {
+	for _, name := range headers {
+		if !hasVary(header, name) {
+			header.Add("Vary", http.CanonicalHeaderKey(name))
+		}
+	}
+}
+
+func hasVary(header http.Header, name string) bool {
+	for _, value := range header["Vary"] {
+		for _, vary := range strings.Split(value, ",") {
+			vary = strings.TrimSpace(vary)
+			if vary == "*" || strings.EqualFold(vary, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// cacheSeconds is the whole number of seconds in d, anything less than a second is dropped
+func cacheSeconds(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	return strconv.FormatInt(int64(d/time.Second), 10)
+}
@@ -0,0 +1,120 @@
+package hapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+func TestRespondCacheControl(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		opts                 []ResponseOption
+		existingVary         []string
+		expectedCacheControl string
+		expectedVary         []string
+	}{
+		{
+			desc: "no cache options",
+		},
+		{
+			desc:                 "public with max ages",
+			opts:                 []ResponseOption{WithCacheControl(CachePublic, CacheMaxAge(time.Minute), CacheSharedMaxAge(time.Hour), CacheStaleWhileRevalidate(30*time.Second))},
+			expectedCacheControl: "public, max-age=60, s-maxage=3600, stale-while-revalidate=30",
+		},
+		{
+			desc:                 "private",
+			opts:                 []ResponseOption{WithCacheControl(CachePrivate, CacheMaxAge(1500*time.Millisecond))},
+			expectedCacheControl: "private, max-age=1",
+		},
+		{
+			desc:                 "max age of 0",
+			opts:                 []ResponseOption{WithCacheControl(CachePrivate, CacheMaxAge(0))},
+			expectedCacheControl: "private, max-age=0",
+		},
+		{
+			desc:                 "no store",
+			opts:                 []ResponseOption{WithCacheControl(CacheNoStore)},
+			expectedCacheControl: "no-store",
+		},
+		{
+			desc:         "vary",
+			opts:         []ResponseOption{WithVary("accept-language", "Authorization"), WithVary("Accept-Language")},
+			expectedVary: []string{"Accept-Language", "Authorization"},
+		},
+		{
+			desc:         "vary keeps existing headers",
+			opts:         []ResponseOption{WithVary("Accept-Encoding", "Accept-Language")},
+			existingVary: []string{"Origin, Accept-Encoding"},
+			expectedVary: []string{"Origin, Accept-Encoding", "Accept-Language"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			for _, vary := range tc.existingVary {
+				recorder.Header().Add("Vary", vary)
+			}
+
+			err := Respond(recorder, http.StatusOK, "hello world", tc.opts...)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCacheControl, recorder.Header().Get("Cache-Control"))
+			assert.Equal(t, tc.expectedVary, recorder.Header()["Vary"])
+		})
+	}
+}
+
+func TestRespondNotModifiedCacheControl(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("If-None-Match", `"v42"`)
+
+	recorder := httptest.NewRecorder()
+	err := Respond(recorder, http.StatusOK, "hello world", WithRequest(req), WithETag("v42"), WithCacheControl(CachePrivate, CacheMaxAge(time.Minute)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Equal(t, "private, max-age=60", recorder.Header().Get("Cache-Control"))
+}
+
+func TestRespondErrorCacheControl(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		opts                 []ResponseOption
+		existingCacheControl string
+		expectedCacheControl string
+	}{
+		{
+			desc:                 "no store by default",
+			expectedCacheControl: "no-store",
+		},
+		{
+			desc:                 "cache control that was already set is replaced",
+			existingCacheControl: "public, max-age=3600",
+			expectedCacheControl: "no-store",
+		},
+		{
+			desc:                 "cache control option wins",
+			opts:                 []ResponseOption{WithCacheControl(CachePublic, CacheMaxAge(time.Minute))},
+			expectedCacheControl: "public, max-age=60",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if tc.existingCacheControl != "" {
+				recorder.Header().Set("Cache-Control", tc.existingCacheControl)
+			}
+
+			err := RespondError(recorder, errors.NotFound.New("user not found"), tc.opts...)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusNotFound, recorder.Code)
+			assert.Equal(t, tc.expectedCacheControl, recorder.Header().Get("Cache-Control"))
+		})
+	}
+}
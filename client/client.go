@@ -0,0 +1,285 @@
+// Package client is a small json http client for calling services that respond with hapi.
+// Non 2xx responses are turned into HapiErrors so they can be passed along with hapi.RespondError.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/thestephenstanton/hapi"
+)
+
+// Client calls a service that speaks hapi
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	header       http.Header
+	maxRetries   int
+	backoff      Backoff
+	maxRetryWait time.Duration
+}
+
+// DefaultMaxRetryWait is the longest the client will wait before a retry
+const DefaultMaxRetryWait = time.Minute
+
+// Backoff returns how long to wait before the retry attempt (starting at 1) when the
+// response didn't have a Retry-After header
+type Backoff func(attempt int) time.Duration
+
+// DefaultBackoff doubles the wait every attempt starting at 100ms, up to 5s
+func DefaultBackoff(attempt int) time.Duration {
+	wait := 100 * time.Millisecond << uint(attempt-1)
+	if wait <= 0 || wait > 5*time.Second {
+		return 5 * time.Second
+	}
+
+	return wait
+}
+
+// Option is an option given to New
+type Option func(c *Client)
+
+// WithHTTPClient sets the http.Client used to make requests, http.DefaultClient is used by default
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader sets a header that is sent with every request
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Set(key, value)
+	}
+}
+
+// WithRetries retries requests that get a 429 or a 503 up to maxRetries times. The Retry-After
+// header is honoured up to WithMaxRetryWait, when there isn't one backoff is used. If backoff is nil, DefaultBackoff is used.
+func WithRetries(maxRetries int, backoff Backoff) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// WithMaxRetryWait sets the longest the client will wait before a retry, it is DefaultMaxRetryWait
+// by default. If Retry-After (or the backoff) asks for a longer wait, the response isn't retried and
+// its HapiError is returned.
+func WithMaxRetryWait(maxRetryWait time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetryWait = maxRetryWait
+	}
+}
+
+// New creates a Client that makes requests relative to baseURL
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		httpClient:   http.DefaultClient,
+		header:       make(http.Header),
+		maxRetryWait: DefaultMaxRetryWait,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.backoff == nil {
+		c.backoff = DefaultBackoff
+	}
+
+	return c
+}
+
+// RequestOption is an option for a single request
+type RequestOption func(r *http.Request)
+
+// WithRequestHeader sets a header on a single request
+func WithRequestHeader(key, value string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set(key, value)
+	}
+}
+
+// Get calls path and decodes the response into target
+func (c *Client) Get(ctx context.Context, path string, target interface{}, opts ...RequestOption) error {
+	return c.Do(ctx, http.MethodGet, path, nil, target, opts...)
+}
+
+// Post sends body to path and decodes the response into target
+func (c *Client) Post(ctx context.Context, path string, body, target interface{}, opts ...RequestOption) error {
+	return c.Do(ctx, http.MethodPost, path, body, target, opts...)
+}
+
+// Put sends body to path and decodes the response into target
+func (c *Client) Put(ctx context.Context, path string, body, target interface{}, opts ...RequestOption) error {
+	return c.Do(ctx, http.MethodPut, path, body, target, opts...)
+}
+
+// Patch sends body to path and decodes the response into target
+func (c *Client) Patch(ctx context.Context, path string, body, target interface{}, opts ...RequestOption) error {
+	return c.Do(ctx, http.MethodPatch, path, body, target, opts...)
+}
+
+// Delete calls path and decodes the response into target
+func (c *Client) Delete(ctx context.Context, path string, target interface{}, opts ...RequestOption) error {
+	return c.Do(ctx, http.MethodDelete, path, nil, target, opts...)
+}
+
+// Do makes the request. If body is not nil, it is marshalled to json. If the response is a 2xx, it is
+// decoded into target (unless target is nil). Any other response is returned as a HapiError with the
+// ErrorType for the status code. The request id in ctx, if there is one, is sent in the X-Request-ID header.
+func (c *Client) Do(ctx context.Context, method, path string, body, target interface{}, opts ...RequestOption) error {
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		bodyBytes, err = json.Marshal(body)
+		if err != nil {
+			return goerrors.Wrap(err, "failed to marshal request body")
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		response, err := c.do(ctx, method, path, bodyBytes, opts)
+		if err != nil {
+			return err
+		}
+
+		if attempt < c.maxRetries && shouldRetry(response.StatusCode) {
+			wait, ok := retryAfter(response.Header.Get("Retry-After"))
+			if !ok {
+				wait = c.backoff(attempt + 1)
+			}
+
+			// the service won't be back any time soon so the caller should hear about it now
+			if wait > c.maxRetryWait {
+				err = decodeResponse(response, target)
+				drain(response)
+
+				return err
+			}
+
+			drain(response)
+
+			err = sleep(ctx, wait)
+			if err != nil {
+				return goerrors.Wrapf(err, "%s %s gave up retrying", method, path)
+			}
+
+			continue
+		}
+
+		err = decodeResponse(response, target)
+		drain(response)
+
+		return err
+	}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, bodyBytes []byte, opts []RequestOption) (*http.Response, error) {
+	var body io.Reader
+	if bodyBytes != nil {
+		body = bytes.NewReader(bodyBytes)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, goerrors.Wrap(err, "failed to create request")
+	}
+
+	for key, values := range c.header {
+		request.Header[key] = values
+	}
+
+	request.Header.Set("Accept", "application/json")
+	if bodyBytes != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	requestID, ok := hapi.RequestIDFromContext(ctx)
+	if ok {
+		request.Header.Set(hapi.RequestIDHeader, requestID)
+	}
+
+	for _, opt := range opts {
+		opt(request)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, goerrors.Wrapf(err, "failed to %s %s", method, request.URL)
+	}
+
+	return response, nil
+}
+
+func decodeResponse(response *http.Response, target interface{}) error {
+	err := hapi.DecodeError(response)
+	if err != nil {
+		return err
+	}
+
+	if target == nil || response.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	err = json.NewDecoder(response.Body).Decode(target)
+	if err != nil && err != io.EOF {
+		return goerrors.Wrap(err, "failed to decode response body")
+	}
+
+	return nil
+}
+
+func shouldRetry(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
+
+// retryAfter parses the Retry-After header which is either seconds or an http date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+
+		return wait, true
+	}
+
+	return 0, false
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drain reads the rest of the body so the connection can be reused
+func drain(response *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, 1<<20))
+	_ = response.Body.Close()
+}
@@ -0,0 +1,253 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+type user struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func noBackoff(attempt int) time.Duration {
+	return 0
+}
+
+func TestDo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/42":
+			_ = hapi.RespondOK(w, user{ID: "42", Name: r.Header.Get("X-Name")})
+		case "/users":
+			var u user
+			err := hapi.UnmarshalBody(r, &u)
+			if err != nil {
+				_ = hapi.RespondError(w, err)
+				return
+			}
+
+			u.ID = r.Header.Get(hapi.RequestIDHeader)
+			_ = hapi.Respond(w, http.StatusCreated, u)
+		default:
+			_ = hapi.RespondError(w, errors.NotFound.New("could not find user"))
+		}
+	}))
+	defer server.Close()
+
+	c := New(server.URL, WithHeader("X-Name", "stephen"))
+
+	t.Run("get decodes into target", func(t *testing.T) {
+		var actual user
+		err := c.Get(context.Background(), "/users/42", &actual)
+
+		assert.NoError(t, err)
+		assert.Equal(t, user{ID: "42", Name: "stephen"}, actual)
+	})
+
+	t.Run("request header overrides client header", func(t *testing.T) {
+		var actual user
+		err := c.Get(context.Background(), "/users/42", &actual, WithRequestHeader("X-Name", "someone else"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, user{ID: "42", Name: "someone else"}, actual)
+	})
+
+	t.Run("post sends body and request id", func(t *testing.T) {
+		ctx := hapi.ContextWithRequestID(context.Background(), "abc-123")
+
+		var actual user
+		err := c.Post(ctx, "/users", user{Name: "stephen"}, &actual)
+
+		assert.NoError(t, err)
+		assert.Equal(t, user{ID: "abc-123", Name: "stephen"}, actual)
+	})
+
+	t.Run("non 2xx is a hapi error", func(t *testing.T) {
+		err := c.Get(context.Background(), "/users/7", nil)
+
+		hapiErr := errors.CastToHapiError(err)
+		assert.Equal(t, errors.NotFound, hapiErr.ErrorType)
+		assert.Equal(t, "could not find user", hapiErr.GetMessage())
+	})
+}
+
+func TestDoRetries(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		maxRetries         int
+		failures           int
+		failureStatusCode  int
+		expectedAttempts   int
+		expectedStatusCode int
+	}{
+		{
+			desc:               "retry 503 until success",
+			maxRetries:         3,
+			failures:           2,
+			failureStatusCode:  http.StatusServiceUnavailable,
+			expectedAttempts:   3,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			desc:               "retry 429 until out of retries",
+			maxRetries:         2,
+			failures:           5,
+			failureStatusCode:  http.StatusTooManyRequests,
+			expectedAttempts:   3,
+			expectedStatusCode: http.StatusTooManyRequests,
+		},
+		{
+			desc:               "don't retry 500",
+			maxRetries:         3,
+			failures:           1,
+			failureStatusCode:  http.StatusInternalServerError,
+			expectedAttempts:   1,
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var attempts int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts <= tc.failures {
+					w.Header().Set("Retry-After", "0")
+					_ = hapi.Respond(w, tc.failureStatusCode, hapi.NewErrorResponse("try again"))
+					return
+				}
+
+				_ = hapi.RespondOK(w, user{ID: "42"})
+			}))
+			defer server.Close()
+
+			c := New(server.URL, WithRetries(tc.maxRetries, noBackoff))
+
+			var actual user
+			err := c.Get(context.Background(), "/users/42", &actual)
+
+			assert.Equal(t, tc.expectedAttempts, attempts)
+			if tc.expectedStatusCode == http.StatusOK {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestDoRetryCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := New(server.URL, WithRetries(1, nil)).Get(ctx, "/", nil)
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestDoRetryAfterTooLong(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		retryAfter       string
+		opts             []Option
+		expectedAttempts int
+	}{
+		{
+			desc:             "longer than the default max",
+			retryAfter:       "86400",
+			expectedAttempts: 1,
+		},
+		{
+			desc:             "longer than the max",
+			retryAfter:       "2",
+			opts:             []Option{WithMaxRetryWait(time.Second)},
+			expectedAttempts: 1,
+		},
+		{
+			desc:             "within the max",
+			retryAfter:       "0",
+			opts:             []Option{WithMaxRetryWait(time.Second)},
+			expectedAttempts: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var attempts int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.Header().Set("Retry-After", tc.retryAfter)
+				_ = hapi.Respond(w, http.StatusServiceUnavailable, hapi.NewErrorResponse("down for maintenance"))
+			}))
+			defer server.Close()
+
+			c := New(server.URL, append(tc.opts, WithRetries(1, noBackoff))...)
+
+			start := time.Now()
+			err := c.Get(context.Background(), "/users/42", nil)
+
+			assert.Less(t, int64(time.Since(start)), int64(time.Second))
+			assert.Equal(t, tc.expectedAttempts, attempts)
+
+			var hapiErr errors.HapiError
+			if assert.True(t, errors.As(err, &hapiErr)) {
+				assert.Equal(t, errors.ServiceUnavailable, hapiErr.ErrorType)
+				assert.Equal(t, "down for maintenance", hapiErr.GetMessage())
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{
+			desc:     "seconds",
+			value:    "3",
+			expected: 3 * time.Second,
+			ok:       true,
+		},
+		{
+			desc:  "http date in the past",
+			value: "Wed, 21 Oct 2015 07:28:00 GMT",
+			ok:    true,
+		},
+		{
+			desc: "empty",
+		},
+		{
+			desc:  "garbage",
+			value: "soon",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			actual, ok := retryAfter(tc.value)
+
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.ok, ok)
+		})
+	}
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	assert.Equal(t, 100*time.Millisecond, DefaultBackoff(1))
+	assert.Equal(t, 400*time.Millisecond, DefaultBackoff(3))
+	assert.Equal(t, 5*time.Second, DefaultBackoff(100))
+}
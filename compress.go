@@ -0,0 +1,318 @@
+package hapi
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultMinCompressSize is the smallest body Compress will compress, anything smaller
+// usually gets bigger once the compression headers are added
+const defaultMinCompressSize = 1024
+
+// Compressor creates a writer that compresses everything written to it into w. If the writer
+// has a Flush() error method, it is used to flush streaming responses.
+type Compressor func(w io.Writer) (io.WriteCloser, error)
+
+// CompressOption is an option given to Compress
+type CompressOption func(c *compressConfig)
+
+type compressConfig struct {
+	// encodings is in order of preference for when the client likes them the same
+	encodings   []string
+	compressors map[string]Compressor
+	minSize     int
+}
+
+// WithCompressor adds a Content-Encoding that Compress can use, e.g. br with a brotli writer. It is
+// preferred over the ones that were added before it, gzip and deflate are there by default.
+func WithCompressor(encoding string, compressor Compressor) CompressOption {
+	return func(c *compressConfig) {
+		encoding = strings.ToLower(encoding)
+
+		if _, ok := c.compressors[encoding]; !ok {
+			c.encodings = append([]string{encoding}, c.encodings...)
+		}
+
+		c.compressors[encoding] = compressor
+	}
+}
+
+// WithMinCompressSize sets the smallest body that will be compressed, it is 1KB by default.
+func WithMinCompressSize(bytes int) CompressOption {
+	return func(c *compressConfig) {
+		c.minSize = bytes
+	}
+}
+
+// Compress creates middleware that compresses responses with the best encoding from the request's
+// Accept-Encoding. Bodies smaller than the min size, ones that already have a Content-Encoding and
+// ones with a Content-Type that is already compressed (images, zips etc.) are sent as they are.
+// Streaming responses are compressed as soon as they are flushed. A strong ETag is made weak when the
+// body is compressed since the bytes sent aren't the ones it was made from.
+func Compress(opts ...CompressOption) func(http.Handler) http.Handler {
+	config := compressConfig{
+		encodings: []string{"gzip", "deflate"},
+		compressors: map[string]Compressor{
+			"gzip": func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriter(w), nil
+			},
+			"deflate": func(w io.Writer) (io.WriteCloser, error) {
+				return zlib.NewWriterLevel(w, zlib.DefaultCompression)
+			},
+		},
+		minSize: defaultMinCompressSize,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the response changes with Accept-Encoding even when it isn't compressed
+			addVary(w.Header(), "Accept-Encoding")
+
+			encoding := config.negotiate(r.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				compressor:     config.compressors[encoding],
+				minSize:        config.minSize,
+			}
+			defer cw.close()
+
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiate picks the encoding the client likes the most, it is empty if there isn't one we both support
+func (c compressConfig) negotiate(acceptEncoding string) string {
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+
+		encoding := strings.ToLower(strings.TrimSpace(fields[0]))
+		if encoding == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+
+			q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err == nil {
+				quality = q
+			}
+		}
+
+		if encoding == "*" {
+			wildcard = quality
+			continue
+		}
+
+		qualities[encoding] = quality
+	}
+
+	best := ""
+	bestQuality := 0.0
+
+	for _, encoding := range c.encodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality = wildcard
+		}
+
+		if quality > bestQuality {
+			best = encoding
+			bestQuality = quality
+		}
+	}
+
+	return best
+}
+
+// compressWriter holds on to the body until it is big enough to be worth compressing, or until it
+// is flushed, and only then writes the headers
+type compressWriter struct {
+	http.ResponseWriter
+
+	encoding   string
+	compressor Compressor
+	minSize    int
+
+	statusCode int
+	buf        []byte
+
+	// started is true once the headers have been written, writer is nil if the body isn't compressed
+	started bool
+	writer  io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(statusCode int) {
+	if cw.started {
+		cw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	// like net/http, only the first status counts
+	if cw.statusCode != 0 {
+		return
+	}
+
+	// informational responses don't end the response, they go straight through
+	if statusCode >= 100 && statusCode < 200 {
+		cw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	cw.statusCode = statusCode
+
+	// there won't be a body to compress
+	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+		cw.start(false)
+	}
+}
+
+func (cw *compressWriter) Write(bytes []byte) (int, error) {
+	if !cw.started {
+		if !cw.compressible() {
+			cw.start(false)
+		} else {
+			cw.buf = append(cw.buf, bytes...)
+			if len(cw.buf) < cw.minSize {
+				return len(bytes), nil
+			}
+
+			// everything is in buf now and gets written by start
+			err := cw.start(true)
+			if err != nil {
+				return 0, err
+			}
+
+			return len(bytes), nil
+		}
+	}
+
+	if cw.writer != nil {
+		return cw.writer.Write(bytes)
+	}
+
+	return cw.ResponseWriter.Write(bytes)
+}
+
+// Flush compresses whatever has been written so far and flushes it to the client so that streaming
+// responses, like server sent events, aren't held back
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		// the headers have to go out now so this is the last chance to compress
+		err := cw.start(cw.compressible())
+		if err != nil {
+			return
+		}
+	}
+
+	flusher, ok := cw.writer.(interface{ Flush() error })
+	if ok {
+		_ = flusher.Flush()
+	}
+
+	httpFlusher, ok := cw.ResponseWriter.(http.Flusher)
+	if ok {
+		httpFlusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController get to the original http.ResponseWriter
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// compressible checks the headers the handler set to see if the body should be compressed
+func (cw *compressWriter) compressible() bool {
+	header := cw.Header()
+
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	contentType := strings.ToLower(header.Get("Content-Type"))
+	for _, prefix := range []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/x-gzip"} {
+		if strings.HasPrefix(contentType, prefix) && contentType != "image/svg+xml" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// start writes the headers and whatever is in buf, compressing it if compress is true
+func (cw *compressWriter) start(compress bool) error {
+	cw.started = true
+
+	if compress {
+		writer, err := cw.compressor(cw.ResponseWriter)
+		if err == nil {
+			cw.writer = writer
+
+			cw.Header().Del("Content-Length")
+			cw.Header().Set("Content-Encoding", cw.encoding)
+
+			// the compressed bytes aren't the ones the strong ETag was made from
+			etag := cw.Header().Get("ETag")
+			if strings.HasPrefix(etag, `"`) {
+				cw.Header().Set("ETag", "W/"+etag)
+			}
+		}
+	}
+
+	if cw.statusCode != 0 {
+		cw.ResponseWriter.WriteHeader(cw.statusCode)
+	}
+
+	if len(cw.buf) == 0 {
+		return nil
+	}
+
+	buf := cw.buf
+	cw.buf = nil
+
+	var err error
+	if cw.writer != nil {
+		_, err = cw.writer.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+
+	return err
+}
+
+// close writes anything that was too small to compress and finishes the compressed body
+func (cw *compressWriter) close() {
+	if !cw.started {
+		if cw.statusCode == 0 && len(cw.buf) == 0 {
+			return
+		}
+
+		_ = cw.start(false)
+	}
+
+	if cw.writer != nil {
+		_ = cw.writer.Close()
+	}
+}
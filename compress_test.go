@@ -0,0 +1,263 @@
+package hapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+type upperWriter struct {
+	w io.Writer
+}
+
+func (u upperWriter) Write(b []byte) (int, error) {
+	return u.w.Write(bytes.ToUpper(b))
+}
+
+func (u upperWriter) Close() error {
+	return nil
+}
+
+func decompress(t *testing.T, encoding string, body []byte) string {
+	var reader io.Reader
+	switch encoding {
+	case "gzip":
+		gzipReader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = gzipReader
+	case "deflate":
+		zlibReader, err := zlib.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = zlibReader
+	default:
+		return string(body)
+	}
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(decompressed)
+}
+
+func TestCompress(t *testing.T) {
+	largePayload := strings.Repeat("gopher", 500)
+
+	testCases := []struct {
+		desc             string
+		acceptEncoding   string
+		opts             []CompressOption
+		handler          http.HandlerFunc
+		expectedEncoding string
+		expectedBody     string
+	}{
+		{
+			desc:             "gzip",
+			acceptEncoding:   "gzip, deflate",
+			handler:          respondHandler(http.StatusOK, largePayload),
+			expectedEncoding: "gzip",
+			expectedBody:     `"` + largePayload + `"`,
+		},
+		{
+			desc:             "deflate is preferred by the client",
+			acceptEncoding:   "gzip;q=0.5, deflate",
+			handler:          respondHandler(http.StatusOK, largePayload),
+			expectedEncoding: "deflate",
+			expectedBody:     `"` + largePayload + `"`,
+		},
+		{
+			desc:             "wildcard",
+			acceptEncoding:   "br, *",
+			handler:          respondHandler(http.StatusOK, largePayload),
+			expectedEncoding: "gzip",
+			expectedBody:     `"` + largePayload + `"`,
+		},
+		{
+			desc:           "encoding is refused",
+			acceptEncoding: "gzip;q=0, deflate;q=0",
+			handler:        respondHandler(http.StatusOK, largePayload),
+			expectedBody:   `"` + largePayload + `"`,
+		},
+		{
+			desc:         "no accept encoding",
+			handler:      respondHandler(http.StatusOK, largePayload),
+			expectedBody: `"` + largePayload + `"`,
+		},
+		{
+			desc:           "small body",
+			acceptEncoding: "gzip",
+			handler:        respondHandler(http.StatusOK, "gopher"),
+			expectedBody:   `"gopher"`,
+		},
+		{
+			desc:             "min size",
+			acceptEncoding:   "gzip",
+			opts:             []CompressOption{WithMinCompressSize(1)},
+			handler:          respondHandler(http.StatusOK, "gopher"),
+			expectedEncoding: "gzip",
+			expectedBody:     `"gopher"`,
+		},
+		{
+			desc:           "already encoded",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "identity")
+				_, _ = w.Write([]byte(largePayload))
+			},
+			expectedEncoding: "identity",
+			expectedBody:     largePayload,
+		},
+		{
+			desc:           "already compressed content type",
+			acceptEncoding: "gzip",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "image/png")
+				_, _ = w.Write([]byte(largePayload))
+			},
+			expectedBody: largePayload,
+		},
+		{
+			desc:             "pluggable compressor",
+			acceptEncoding:   "gzip, br",
+			opts:             []CompressOption{WithCompressor("br", func(w io.Writer) (io.WriteCloser, error) { return upperWriter{w: w}, nil })},
+			handler:          respondHandler(http.StatusOK, largePayload),
+			expectedEncoding: "br",
+			expectedBody:     `"` + strings.ToUpper(largePayload) + `"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tc.acceptEncoding)
+			}
+
+			recorder := httptest.NewRecorder()
+			Compress(tc.opts...)(tc.handler).ServeHTTP(recorder, req)
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			assert.Equal(t, tc.expectedEncoding, recorder.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", recorder.Header().Get("Vary"))
+			assert.Equal(t, tc.expectedBody, decompress(t, tc.expectedEncoding, recorder.Body.Bytes()))
+		})
+	}
+}
+
+func TestCompressRespondError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	recorder := httptest.NewRecorder()
+	handler := Compress(WithMinCompressSize(1))(http.HandlerFunc(respondErrorHandler(errors.NotFound.New("user not found"))))
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"user not found"}`, decompress(t, "gzip", recorder.Body.Bytes()))
+}
+
+func TestCompressETag(t *testing.T) {
+	payload := map[string]string{"name": strings.Repeat("gopher", 500)}
+
+	handler := Compress()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = Respond(w, http.StatusOK, payload, WithRequest(r), WithBodyETag(false))
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	etag := recorder.Header().Get("ETag")
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+	assert.True(t, strings.HasPrefix(etag, `W/"`), etag)
+
+	// the weak ETag still matches If-None-Match
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", etag)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.Bytes())
+}
+
+func TestCompressNoBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	recorder := httptest.NewRecorder()
+	handler := Compress()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, "", recorder.Header().Get("Content-Encoding"))
+	assert.Empty(t, recorder.Body.Bytes())
+}
+
+func TestCompressFlush(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	recorder := httptest.NewRecorder()
+	var flushedBody []byte
+
+	handler := Compress()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: hello\n\n"))
+
+		w.(http.Flusher).Flush()
+		flushedBody = append([]byte(nil), recorder.Body.Bytes()...)
+
+		_, _ = w.Write([]byte("data: world\n\n"))
+	}))
+	handler.ServeHTTP(recorder, req)
+
+	assert.True(t, recorder.Flushed)
+	assert.Equal(t, "gzip", recorder.Header().Get("Content-Encoding"))
+
+	// the first event has to reach the client before the handler is done
+	gzipReader, err := gzip.NewReader(bytes.NewReader(flushedBody))
+	if assert.NoError(t, err) {
+		event := make([]byte, len("data: hello\n\n"))
+		_, err = io.ReadFull(gzipReader, event)
+		assert.NoError(t, err)
+		assert.Equal(t, "data: hello\n\n", string(event))
+	}
+
+	assert.Equal(t, "data: hello\n\ndata: world\n\n", decompress(t, "gzip", recorder.Body.Bytes()))
+}
+
+func TestCompressUnwrap(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	metrics := &MemoryMetrics{}
+	handler := Instrument(metrics, "/")(Compress()(http.HandlerFunc(respondErrorHandler(errors.NotFound.New("user not found")))))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	// the error type can only be recorded if Compress lets recordErrorType unwrap it
+	if assert.Len(t, metrics.Metrics(), 1) {
+		assert.Equal(t, http.StatusNotFound, metrics.Metrics()[0].StatusCode)
+		assert.Equal(t, errors.NotFound, metrics.Metrics()[0].ErrorType)
+	}
+}
@@ -1,6 +1,10 @@
 package hapi
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/thestephenstanton/hapi/errors"
+)
 
 // Config configs hapi
 var Config = struct {
@@ -8,9 +12,40 @@ var Config = struct {
 	DefaultStatusCode   int
 	ReturnNulls         bool
 	ReturnRawError      bool
+
+	// ReturnErrorType adds the name of the error's ErrorType to error responses, like
+	// ReturnRawError it is meant for development and tests (see hapitest.AssertErrorType)
+	ReturnErrorType bool
+
+	// ErrorHooks are called, in order, every time an error response is written
+	ErrorHooks []ErrorHook
+
+	// ErrorReporter gets every error response with a status code of at least ReportMinStatusCode
+	ErrorReporter       ErrorReporter
+	ReportMinStatusCode int
+
+	// ErrorMappers turn errors that aren't HapiErrors, like sql.ErrNoRows, into HapiErrors
+	// before RespondError responds. Use RegisterErrorMapper to add your own.
+	ErrorMappers []ErrorMapper
+
+	// SanitizeServerErrors replaces the message of 5xx errors with the ErrorType's message in
+	// DefaultMessages, or DefaultErrorMessage, so things like queries don't leak to clients. Errors
+	// made with Public or marked with MarkSafe are left alone. Hooks still get the original message.
+	SanitizeServerErrors bool
+	DefaultMessages      map[errors.ErrorType]string
+
+	// MultiErrorPrecedence decides which error in a MultiError picks the status code when the
+	// MultiError doesn't have its own Precedence, see errors.MultiError
+	MultiErrorPrecedence []errors.ErrorType
+
+	// Catalog translates the message keys of HapiErrors for the languages in the request's
+	// Accept-Language header, the request has to be given with WithRequest
+	Catalog Catalog
 }{
 	DefaultErrorMessage: "uh oh, something went wrong, please try again later",
 	DefaultStatusCode:   http.StatusInternalServerError,
 	ReturnNulls:         false,
 	ReturnRawError:      false,
+	ReportMinStatusCode: http.StatusInternalServerError,
+	ErrorMappers:        DefaultErrorMappers,
 }
@@ -0,0 +1,74 @@
+package hapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+// maxErrorBodySize is how much of an error response body we are willing to read
+const maxErrorBodySize = 1 << 20
+
+// problemDetails is the body of an application/problem+json response (RFC 7807)
+type problemDetails struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+// DecodeError turns a non 2xx response into a HapiError so that errors from other services
+// can be passed along with RespondError. It decodes the body as an ErrorResponse or as
+// application/problem+json and the ErrorType comes from the status code. If the response
+// is a 2xx, it will return nil. The body is read but not closed.
+func DecodeError(response *http.Response) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	var body []byte
+	if response.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+		if err != nil {
+			return errors.InternalServerError.Wrap(err, "failed to read error response body")
+		}
+	}
+
+	message, rawError := decodeErrorMessage(body)
+	if message == "" {
+		message = http.StatusText(response.StatusCode)
+	}
+
+	err := goerrors.Errorf("upstream responded with %d: %s", response.StatusCode, message)
+	if rawError != "" {
+		err = goerrors.Wrap(goerrors.New(rawError), err.Error())
+	}
+
+	if response.Request != nil {
+		err = goerrors.Wrap(err, fmt.Sprintf("%s %s", response.Request.Method, response.Request.URL))
+	}
+
+	return errors.FromStatusCode(response.StatusCode).Cast(err, message)
+}
+
+func decodeErrorMessage(body []byte) (string, string) {
+	var errorResponse ErrorResponse
+	err := json.Unmarshal(body, &errorResponse)
+	if err == nil && errorResponse.ErrorMessage != "" {
+		return errorResponse.ErrorMessage, errorResponse.RawError
+	}
+
+	var problem problemDetails
+	err = json.Unmarshal(body, &problem)
+	if err == nil && problem.Detail != "" {
+		return problem.Detail, ""
+	}
+	if err == nil && problem.Title != "" {
+		return problem.Title, ""
+	}
+
+	return "", ""
+}
@@ -0,0 +1,84 @@
+package hapi
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+func newResponse(statusCode int, body string) *http.Response {
+	request, _ := http.NewRequest(http.MethodGet, "http://users.internal/users/42", nil)
+
+	return &http.Response{
+		StatusCode: statusCode,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    request,
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		response          *http.Response
+		expectNil         bool
+		expectedErrorType errors.ErrorType
+		expectedMessage   string
+		expectedError     string
+	}{
+		{
+			desc:      "2xx is not an error",
+			response:  newResponse(http.StatusOK, `{"id":"42"}`),
+			expectNil: true,
+		},
+		{
+			desc:              "hapi error response",
+			response:          newResponse(http.StatusNotFound, `{"error":"could not find user"}`),
+			expectedErrorType: errors.NotFound,
+			expectedMessage:   "could not find user",
+			expectedError:     "GET http://users.internal/users/42: upstream responded with 404: could not find user",
+		},
+		{
+			desc:              "hapi error response with raw error",
+			response:          newResponse(http.StatusInternalServerError, `{"error":"oops","rawError":"connection refused"}`),
+			expectedErrorType: errors.InternalServerError,
+			expectedMessage:   "oops",
+			expectedError:     "GET http://users.internal/users/42: upstream responded with 500: oops: connection refused",
+		},
+		{
+			desc:              "problem json response",
+			response:          newResponse(http.StatusForbidden, `{"type":"about:blank","title":"Forbidden","detail":"you can't see this user"}`),
+			expectedErrorType: errors.Forbidden,
+			expectedMessage:   "you can't see this user",
+			expectedError:     "GET http://users.internal/users/42: upstream responded with 403: you can't see this user",
+		},
+		{
+			desc:              "body that isn't json",
+			response:          newResponse(http.StatusBadRequest, `<html>bad request</html>`),
+			expectedErrorType: errors.BadRequest,
+			expectedMessage:   "Bad Request",
+			expectedError:     "GET http://users.internal/users/42: upstream responded with 400: Bad Request",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := DecodeError(tc.response)
+			if tc.expectNil {
+				assert.NoError(t, err)
+				return
+			}
+
+			hapiErr, ok := err.(errors.HapiError)
+			if !assert.True(t, ok, "should be a HapiError") {
+				return
+			}
+
+			assert.Equal(t, tc.expectedErrorType, hapiErr.ErrorType)
+			assert.Equal(t, tc.expectedMessage, hapiErr.GetMessage())
+			assert.EqualError(t, hapiErr, tc.expectedError)
+		})
+	}
+}
@@ -4,6 +4,18 @@ package hapi
 type ErrorResponse struct {
 	ErrorMessage string `json:"error"`
 	RawError     string `json:"rawError,omitempty"`
+	RequestID    string `json:"requestId,omitempty"`
+	ErrorType    string `json:"type,omitempty"`
+
+	// Retryable tells the client it can retry the request, RetryAfter is how many seconds
+	// it should wait first
+	Retryable  bool `json:"retryable,omitempty"`
+	RetryAfter int  `json:"retryAfter,omitempty"`
+
+	// Status and Errors are used when responding with a MultiError, every error
+	// in it gets its own ErrorResponse with its own status
+	Status int             `json:"status,omitempty"`
+	Errors []ErrorResponse `json:"errors,omitempty"`
 }
 
 // NewErrorResponse creates new ErrorResponse with an error message.NewErrorResponse.
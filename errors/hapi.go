@@ -1,5 +1,14 @@
 package errors
 
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
 // HapiError is a custom error that helps deliver status codes from deeper in
 // code to your application layer
 type HapiError struct {
@@ -7,8 +16,27 @@ type HapiError struct {
 	ErrorType ErrorType
 
 	// Message is the original message that you pass to create the new hapi error
-	// example errors.BadRequest.Wrap(err, "this would be the message")
+	// example errors.BadRequest.Wrap(err, "this would be the message").
+	// It is what RespondError sends to the client, anything internal belongs in Err (see Internal).
 	Message string
+
+	// headers are written to the response by RespondError, it is a pointer so that
+	// HapiError stays comparable
+	headers *http.Header
+
+	// safe means Message was meant for the client so hapi won't sanitize it
+	safe bool
+
+	// messageKey is used to look up a translated message for the client, see WithMessageKey
+	messageKey *messageKey
+
+	// retry overrides whether the ErrorType is retryable, see WithRetry
+	retry *retryHint
+}
+
+type messageKey struct {
+	key  string
+	args map[string]interface{}
 }
 
 // Error returns the error string of a HapiError.
@@ -16,6 +44,11 @@ func (e HapiError) Error() string {
 	return e.Err.Error()
 }
 
+// Unwrap returns the underlying error so that Is and As can walk the whole chain.
+func (e HapiError) Unwrap() error {
+	return e.Err
+}
+
 // GetStatusCode gets the status code for the HapiError.
 func (e HapiError) GetStatusCode() int {
 	return getStatusCode(e.ErrorType)
@@ -33,6 +66,99 @@ func (e HapiError) SetMessage(message string) HapiError {
 	return e
 }
 
+// MarkSafe returns new error with the Message marked as safe for the client, so it is still
+// sent when hapi.Config.SanitizeServerErrors is on.
+func (e HapiError) MarkSafe() HapiError {
+	e.safe = true
+
+	return e
+}
+
+// IsSafe reports if the Message was marked as safe for the client.
+func (e HapiError) IsSafe() bool {
+	return e.safe
+}
+
+// WithMessageKey returns new error with a key to look up a translated message for the client
+// in hapi.Config.Catalog. args are given to the message template. If there is no translation
+// for the client's language, Message is sent instead.
+func (e HapiError) WithMessageKey(key string, args map[string]interface{}) HapiError {
+	e.messageKey = &messageKey{
+		key:  key,
+		args: args,
+	}
+
+	return e
+}
+
+// GetMessageKey gets the message key and its args, the key is empty if there isn't one.
+func (e HapiError) GetMessageKey() (string, map[string]interface{}) {
+	if e.messageKey == nil {
+		return "", nil
+	}
+
+	return e.messageKey.key, e.messageKey.args
+}
+
+// Internal returns new error with internal context added to the raw error. The Message sent
+// to the client doesn't change, so it is safe to put ids, queries etc. in here. It is only
+// ever returned to the client when hapi.Config.ReturnRawError is on.
+func (e HapiError) Internal(format string, args ...interface{}) HapiError {
+	if e.Err == nil {
+		e.Err = errors.Errorf(format, args...)
+		return e
+	}
+
+	e.Err = errors.Wrapf(e.Err, format, args...)
+
+	return e
+}
+
+// GetHeaders gets the headers that will be written to the response.
+func (e HapiError) GetHeaders() http.Header {
+	if e.headers == nil {
+		return nil
+	}
+
+	return *e.headers
+}
+
+// WithHeader returns new error with the response header set.
+func (e HapiError) WithHeader(key, value string) HapiError {
+	headers := e.GetHeaders().Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+
+	headers.Set(key, value)
+	e.headers = &headers
+
+	return e
+}
+
+// WithRetryAfter returns new error with the Retry-After header set to wait, rounded up
+// to the second. Use it with TooManyRequests and ServiceUnavailable.
+func (e HapiError) WithRetryAfter(wait time.Duration) HapiError {
+	e.retry = &retryHint{
+		retryable: e.IsRetryable(),
+		temporary: e.IsTemporary(),
+		after:     wait,
+	}
+
+	return e.WithHeader("Retry-After", strconv.Itoa(RetryAfterSeconds(wait)))
+}
+
+// WithAuthenticate returns new error with the WWW-Authenticate header set to challenge
+// (e.g. `Bearer realm="api"`). Use it with Unauthorized.
+func (e HapiError) WithAuthenticate(challenge string) HapiError {
+	return e.WithHeader("WWW-Authenticate", challenge)
+}
+
+// WithAllow returns new error with the Allow header set to methods. Use it with MethodNotAllowed.
+func (e HapiError) WithAllow(methods ...string) HapiError {
+	return e.WithHeader("Allow", strings.Join(methods, ", "))
+}
+
 // SetMessage will set the Message of a HapiError so that you
 // can return a detailed message for the client when responding.
 // If err is not of type HapiError, it will be converted to a NoType
@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"net/http"
 	"testing"
+	"time"
 
 	goerrors "github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -122,3 +124,176 @@ func TestCastToHapiError(t *testing.T) {
 		})
 	}
 }
+
+func TestUnwrap(t *testing.T) {
+	sentinelErr := goerrors.New("the og error")
+
+	testCases := []struct {
+		desc     string
+		err      error
+		target   error
+		expected bool
+	}{
+		{
+			desc:     "Find error wrapped by hapi error",
+			err:      NotFound.Wrap(sentinelErr, "could not find it"),
+			target:   sentinelErr,
+			expected: true,
+		},
+		{
+			desc:     "Find error wrapped by hapi error wrapped by hapi error",
+			err:      InternalServerError.Wrap(BadRequest.Cast(sentinelErr, "bad"), "oops"),
+			target:   sentinelErr,
+			expected: true,
+		},
+		{
+			desc:     "Different error",
+			err:      NotFound.New("could not find it"),
+			target:   sentinelErr,
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, Is(tc.err, tc.target))
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      HapiError
+		expected http.Header
+	}{
+		{
+			desc:     "No headers",
+			err:      NotFound.New("could not find it"),
+			expected: nil,
+		},
+		{
+			desc: "Custom header",
+			err:  BadRequest.New("bad").WithHeader("x-custom", "custom"),
+			expected: http.Header{
+				"X-Custom": []string{"custom"},
+			},
+		},
+		{
+			desc: "Retry after rounds up",
+			err:  TooManyRequests.New("slow down").WithRetryAfter(1500 * time.Millisecond),
+			expected: http.Header{
+				"Retry-After": []string{"2"},
+			},
+		},
+		{
+			desc: "Authenticate",
+			err:  Unauthorized.New("who are you").WithAuthenticate(`Bearer realm="api"`),
+			expected: http.Header{
+				"Www-Authenticate": []string{`Bearer realm="api"`},
+			},
+		},
+		{
+			desc: "Allow and another header",
+			err:  MethodNotAllowed.New("nope").WithAllow(http.MethodGet, http.MethodPost).WithHeader("X-Custom", "custom"),
+			expected: http.Header{
+				"Allow":    []string{"GET, POST"},
+				"X-Custom": []string{"custom"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.err.GetHeaders())
+		})
+	}
+}
+
+func TestWithHeaderDoesNotChangeOriginal(t *testing.T) {
+	original := ServiceUnavailable.New("down for maintenance").WithRetryAfter(time.Minute)
+	changed := original.WithRetryAfter(time.Hour)
+
+	assert.Equal(t, "60", original.GetHeaders().Get("Retry-After"))
+	assert.Equal(t, "3600", changed.GetHeaders().Get("Retry-After"))
+
+	// HapiError has to stay comparable so people can == their errors
+	var err error = changed
+	assert.True(t, err == error(changed))
+}
+
+func TestInternal(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		err             HapiError
+		expectedError   string
+		expectedMessage string
+	}{
+		{
+			desc:            "Public with internal",
+			err:             NotFound.Public("user not found").Internal("id=%s shard=%d", "42", 7),
+			expectedError:   "id=42 shard=7: user not found",
+			expectedMessage: "user not found",
+		},
+		{
+			desc:            "Wrapped with internal",
+			err:             InternalServerError.Wrap(goerrors.New("connection refused"), "failed to save user").Internal("query=%s", "INSERT INTO users"),
+			expectedError:   "query=INSERT INTO users: failed to save user: connection refused",
+			expectedMessage: "failed to save user",
+		},
+		{
+			desc:            "No raw error yet",
+			err:             HapiError{ErrorType: BadRequest, Message: "bad"}.Internal("field=%s", "name"),
+			expectedError:   "field=name",
+			expectedMessage: "bad",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.EqualError(t, tc.err, tc.expectedError)
+			assert.Equal(t, tc.expectedMessage, tc.err.GetMessage())
+		})
+	}
+}
+
+func TestMarkSafe(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		err      HapiError
+		expected bool
+	}{
+		{
+			desc:     "New is not safe",
+			err:      InternalServerError.New("query failed"),
+			expected: false,
+		},
+		{
+			desc:     "Public is safe",
+			err:      InternalServerError.Public("we are having trouble, try again soon"),
+			expected: true,
+		},
+		{
+			desc:     "Marked safe",
+			err:      InternalServerError.New("we are having trouble, try again soon").MarkSafe(),
+			expected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.err.IsSafe())
+		})
+	}
+}
+
+func TestWithMessageKey(t *testing.T) {
+	err := NotFound.New("could not find user")
+
+	key, args := err.GetMessageKey()
+	assert.Equal(t, "", key)
+	assert.Nil(t, args)
+
+	keyed := err.WithMessageKey("user.notFound", map[string]interface{}{"id": "42"})
+
+	key, args = keyed.GetMessageKey()
+	assert.Equal(t, "user.notFound", key)
+	assert.Equal(t, map[string]interface{}{"id": "42"}, args)
+	assert.Equal(t, "could not find user", keyed.GetMessage())
+}
@@ -0,0 +1,164 @@
+package errors
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// hapiErrorJSON is what a HapiError looks like when it is marshalled
+type hapiErrorJSON struct {
+	ErrorType   string                 `json:"type"`
+	StatusCode  int                    `json:"status"`
+	Message     string                 `json:"message,omitempty"`
+	Causes      []string               `json:"causes,omitempty"`
+	Headers     http.Header            `json:"headers,omitempty"`
+	Safe        bool                   `json:"safe,omitempty"`
+	MessageKey  string                 `json:"messageKey,omitempty"`
+	MessageArgs map[string]interface{} `json:"messageArgs,omitempty"`
+	Retry       *retryHintJSON         `json:"retry,omitempty"`
+}
+
+type retryHintJSON struct {
+	Retryable bool   `json:"retryable"`
+	Temporary bool   `json:"temporary"`
+	After     string `json:"after,omitempty"`
+}
+
+// causeError stands in for an error that went through MarshalJSON, only its string survives
+type causeError struct {
+	message string
+	cause   error
+}
+
+func (e causeError) Error() string {
+	return e.message
+}
+
+func (e causeError) Unwrap() error {
+	return e.cause
+}
+
+// MarshalJSON marshals the ErrorType, status code, message, headers, message key, retry hint and
+// the strings of the cause chain so the error can be stored and responded with later, e.g. by a
+// worker that runs a job long after the request. The errors in the chain don't keep their types.
+func (e HapiError) MarshalJSON() ([]byte, error) {
+	key, args := e.GetMessageKey()
+
+	data := hapiErrorJSON{
+		ErrorType:   e.ErrorType.String(),
+		StatusCode:  e.GetStatusCode(),
+		Message:     e.Message,
+		Causes:      causes(e.Err),
+		Headers:     e.GetHeaders(),
+		Safe:        e.safe,
+		MessageKey:  key,
+		MessageArgs: args,
+	}
+
+	if e.retry != nil {
+		data.Retry = &retryHintJSON{
+			Retryable: e.retry.retryable,
+			Temporary: e.retry.temporary,
+		}
+
+		if e.retry.after > 0 {
+			data.Retry.After = e.retry.after.String()
+		}
+	}
+
+	return json.Marshal(data)
+}
+
+// UnmarshalJSON is the opposite of MarshalJSON. If the ErrorType isn't known, it comes from the status code.
+func (e *HapiError) UnmarshalJSON(bytes []byte) error {
+	var data hapiErrorJSON
+
+	err := json.Unmarshal(bytes, &data)
+	if err != nil {
+		return err
+	}
+
+	errorType, ok := ParseErrorType(data.ErrorType)
+	if !ok {
+		errorType = FromStatusCode(data.StatusCode)
+	}
+
+	hapiErr := HapiError{
+		ErrorType: errorType,
+		Message:   data.Message,
+		safe:      data.Safe,
+	}
+
+	// rebuild the chain from the inside out
+	for i := len(data.Causes) - 1; i >= 0; i-- {
+		hapiErr.Err = causeError{
+			message: data.Causes[i],
+			cause:   hapiErr.Err,
+		}
+	}
+
+	// Error and the hooks need an Err, so without a chain the message has to do
+	if hapiErr.Err == nil {
+		message := data.Message
+		if message == "" {
+			message = http.StatusText(hapiErr.GetStatusCode())
+		}
+
+		hapiErr.Err = causeError{message: message}
+	}
+
+	if len(data.Headers) > 0 {
+		headers := data.Headers
+		hapiErr.headers = &headers
+	}
+
+	if data.MessageKey != "" {
+		hapiErr = hapiErr.WithMessageKey(data.MessageKey, data.MessageArgs)
+	}
+
+	if data.Retry != nil {
+		hapiErr.retry = &retryHint{
+			retryable: data.Retry.Retryable,
+			temporary: data.Retry.Temporary,
+		}
+
+		if data.Retry.After != "" {
+			hapiErr.retry.after, err = time.ParseDuration(data.Retry.After)
+			if err != nil {
+				return Wrap(err, "failed to parse retry after")
+			}
+		}
+	}
+
+	*e = hapiErr
+
+	return nil
+}
+
+// MarshalBinary is the same as MarshalJSON.
+func (e HapiError) MarshalBinary() ([]byte, error) {
+	return e.MarshalJSON()
+}
+
+// UnmarshalBinary is the same as UnmarshalJSON.
+func (e *HapiError) UnmarshalBinary(data []byte) error {
+	return e.UnmarshalJSON(data)
+}
+
+// causes gets the string of every error in err's chain. Wrappers that don't change the
+// string, like the ones that only add a stack, are skipped.
+func causes(err error) []string {
+	var messages []string
+
+	for err != nil {
+		message := err.Error()
+		if len(messages) == 0 || messages[len(messages)-1] != message {
+			messages = append(messages, message)
+		}
+
+		err = Unwrap(err)
+	}
+
+	return messages
+}
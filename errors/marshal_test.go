@@ -0,0 +1,219 @@
+package errors
+
+import (
+	"encoding"
+	"encoding/json"
+	"testing"
+	"time"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ json.Marshaler             = HapiError{}
+	_ json.Unmarshaler           = &HapiError{}
+	_ encoding.BinaryMarshaler   = HapiError{}
+	_ encoding.BinaryUnmarshaler = &HapiError{}
+)
+
+func TestMarshalJSON(t *testing.T) {
+	hapiErr := NotFound.Wrap(goerrors.New("sql: no rows in result set"), "user not found").Internal("id=42")
+
+	bytes, err := json.Marshal(hapiErr)
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "NotFound",
+		"status": 404,
+		"message": "user not found",
+		"causes": [
+			"id=42: user not found: sql: no rows in result set",
+			"user not found: sql: no rows in result set",
+			"sql: no rows in result set"
+		]
+	}`, string(bytes))
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		hapiErr HapiError
+	}{
+		{
+			desc:    "new error",
+			hapiErr: BadRequest.New("name is required"),
+		},
+		{
+			desc:    "wrapped error",
+			hapiErr: InternalServerError.Wrap(goerrors.New("connection refused"), "failed to save user"),
+		},
+		{
+			desc:    "public error with internal context",
+			hapiErr: NotFound.Public("user not found").Internal("id=%d", 42),
+		},
+		{
+			desc:    "headers and retry after",
+			hapiErr: ServiceUnavailable.New("down for maintenance").WithRetryAfter(90 * time.Second),
+		},
+		{
+			desc:    "not retryable",
+			hapiErr: GatewayTimeout.New("upstream timed out").WithoutRetry(),
+		},
+		{
+			desc:    "retryable without retry after",
+			hapiErr: Forbidden.New("not yet").WithRetry(0),
+		},
+		{
+			desc:    "message key",
+			hapiErr: BadRequest.New("name is required").WithMessageKey("validation.required", map[string]interface{}{"field": "name"}),
+		},
+		{
+			desc:    "authenticate header",
+			hapiErr: Unauthorized.New("token expired").WithAuthenticate(`Bearer realm="api"`),
+		},
+		{
+			desc:    "no type",
+			hapiErr: CastToHapiError(goerrors.New("some go error")),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			bytes, err := json.Marshal(tc.hapiErr)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var actual HapiError
+			err = json.Unmarshal(bytes, &actual)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tc.hapiErr.ErrorType, actual.ErrorType)
+			assert.Equal(t, tc.hapiErr.GetStatusCode(), actual.GetStatusCode())
+			assert.Equal(t, tc.hapiErr.GetMessage(), actual.GetMessage())
+			assert.Equal(t, tc.hapiErr.Error(), actual.Error())
+			assert.Equal(t, tc.hapiErr.GetHeaders(), actual.GetHeaders())
+			assert.Equal(t, tc.hapiErr.IsSafe(), actual.IsSafe())
+			assert.Equal(t, tc.hapiErr.IsRetryable(), actual.IsRetryable())
+			assert.Equal(t, tc.hapiErr.IsTemporary(), actual.IsTemporary())
+			assert.Equal(t, tc.hapiErr.GetRetryAfter(), actual.GetRetryAfter())
+
+			expectedKey, expectedArgs := tc.hapiErr.GetMessageKey()
+			actualKey, actualArgs := actual.GetMessageKey()
+			assert.Equal(t, expectedKey, actualKey)
+			assert.Equal(t, expectedArgs, actualArgs)
+		})
+	}
+}
+
+func TestUnmarshalJSONCauseChain(t *testing.T) {
+	hapiErr := InternalServerError.Wrap(goerrors.New("connection refused"), "failed to save user")
+
+	bytes, err := json.Marshal(hapiErr)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var actual HapiError
+	err = json.Unmarshal(bytes, &actual)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.EqualError(t, actual, "failed to save user: connection refused")
+	assert.EqualError(t, Unwrap(actual.Err), "connection refused")
+	assert.Nil(t, Unwrap(Unwrap(actual.Err)))
+}
+
+func TestUnmarshalJSONUnknownType(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		data              string
+		expectedErrorType ErrorType
+	}{
+		{
+			desc:              "known status code",
+			data:              `{"type":"Gone","status":404,"message":"it's gone"}`,
+			expectedErrorType: NotFound,
+		},
+		{
+			desc:              "unknown 4xx status code",
+			data:              `{"type":"Gone","status":410,"message":"it's gone"}`,
+			expectedErrorType: BadRequest,
+		},
+		{
+			desc:              "no status code",
+			data:              `{"message":"it's gone"}`,
+			expectedErrorType: NoType,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var actual HapiError
+			err := json.Unmarshal([]byte(tc.data), &actual)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedErrorType, actual.ErrorType)
+			assert.Equal(t, "it's gone", actual.GetMessage())
+		})
+	}
+}
+
+func TestUnmarshalJSONBadRetryAfter(t *testing.T) {
+	var actual HapiError
+	err := json.Unmarshal([]byte(`{"type":"TooManyRequests","status":429,"retry":{"retryable":true,"after":"soon"}}`), &actual)
+
+	assert.Error(t, err)
+}
+
+func TestMarshalBinary(t *testing.T) {
+	hapiErr := TooManyRequests.New("slow down").WithRetryAfter(time.Minute)
+
+	bytes, err := hapiErr.MarshalBinary()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var actual HapiError
+	err = actual.UnmarshalBinary(bytes)
+
+	assert.NoError(t, err)
+	assert.Equal(t, TooManyRequests, actual.ErrorType)
+	assert.Equal(t, "slow down", actual.GetMessage())
+	assert.Equal(t, time.Minute, actual.GetRetryAfter())
+	assert.Equal(t, "60", actual.GetHeaders().Get("Retry-After"))
+}
+
+func TestUnmarshalJSONWithoutCauses(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		data          string
+		expectedError string
+	}{
+		{
+			desc:          "message only",
+			data:          `{"type":"NotFound","status":404,"message":"user not found"}`,
+			expectedError: "user not found",
+		},
+		{
+			desc:          "no message",
+			data:          `{"type":"NotFound","status":404}`,
+			expectedError: "Not Found",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var actual HapiError
+			err := json.Unmarshal([]byte(tc.data), &actual)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, NotFound, actual.ErrorType)
+			assert.EqualError(t, actual, tc.expectedError)
+			assert.Nil(t, Unwrap(actual.Err))
+		})
+	}
+}
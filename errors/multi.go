@@ -0,0 +1,138 @@
+package errors
+
+import (
+	"net/http"
+	"strings"
+)
+
+// MultiError is a group of errors, like every failed validation or every failed item in a batch.
+// It works with the standard library's errors.Is and errors.As just like errors.Join does.
+type MultiError struct {
+	Errs []error
+
+	// Message is sent to the client, if it is empty the status text is sent instead
+	Message string
+
+	// Precedence decides which error picks the status code. ErrorTypes earlier in the list win over
+	// later ones and anything in the list wins over anything that isn't. When neither error is in the
+	// list, the higher status code wins. RespondError uses hapi.Config.MultiErrorPrecedence if it is nil.
+	Precedence []ErrorType
+}
+
+// Join creates a MultiError from errs, leaving out any nil errors. If every error is nil,
+// Join returns nil.
+func Join(errs ...error) error {
+	var nonNil []error
+	for _, err := range errs {
+		if err != nil {
+			nonNil = append(nonNil, err)
+		}
+	}
+
+	if len(nonNil) == 0 {
+		return nil
+	}
+
+	return &MultiError{
+		Errs: nonNil,
+	}
+}
+
+// Error returns the error strings of every error on a new line, the same as errors.Join.
+func (m *MultiError) Error() string {
+	messages := make([]string, 0, len(m.Errs))
+	for _, err := range m.Errs {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the errors so that Is and As check every one of them.
+func (m *MultiError) Unwrap() []error {
+	return m.Errs
+}
+
+// GetStatusCode gets the status code of the error that wins by Precedence.
+func (m *MultiError) GetStatusCode() int {
+	statusCode, _ := m.winner(m.Precedence)
+
+	return statusCode
+}
+
+// GetErrorType gets the ErrorType of the error that wins by Precedence.
+func (m *MultiError) GetErrorType() ErrorType {
+	_, errorType := m.winner(m.Precedence)
+
+	return errorType
+}
+
+// GetMessage gets the Message of the MultiError.
+func (m *MultiError) GetMessage() string {
+	return m.Message
+}
+
+// winner uses the same precedence for nested MultiErrors so the outer one decides
+func (m *MultiError) winner(precedence []ErrorType) (int, ErrorType) {
+	statusCode := http.StatusInternalServerError
+	errorType := NoType
+
+	found := false
+	for _, err := range m.Errs {
+		if err == nil {
+			continue
+		}
+
+		errStatusCode, errErrorType := statusAndType(err, precedence)
+		if !found || outranks(precedence, errErrorType, errStatusCode, errorType, statusCode) {
+			statusCode = errStatusCode
+			errorType = errErrorType
+			found = true
+		}
+	}
+
+	return statusCode, errorType
+}
+
+func statusAndType(err error, precedence []ErrorType) (int, ErrorType) {
+	switch e := err.(type) {
+	case HapiError:
+		return e.GetStatusCode(), e.ErrorType
+	case *MultiError:
+		return e.winner(precedence)
+	case interface{ GetStatusCode() int }:
+		return e.GetStatusCode(), FromStatusCode(e.GetStatusCode())
+	case interface{ Unwrap() []error }:
+		return (&MultiError{Errs: e.Unwrap()}).winner(precedence)
+	default:
+		return http.StatusInternalServerError, NoType
+	}
+}
+
+func outranks(precedence []ErrorType, errorType ErrorType, statusCode int, otherType ErrorType, otherStatusCode int) bool {
+	index := precedenceIndex(precedence, errorType)
+	otherIndex := precedenceIndex(precedence, otherType)
+
+	switch {
+	case index == otherIndex:
+		return statusCode > otherStatusCode
+	case index == -1:
+		return false
+	case otherIndex == -1:
+		return true
+	default:
+		return index < otherIndex
+	}
+}
+
+func precedenceIndex(precedence []ErrorType, errorType ErrorType) int {
+	for i, t := range precedence {
+		if t == errorType {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,93 @@
+package errors
+
+import (
+	stderrors "errors"
+	"net/http"
+	"testing"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoin(t *testing.T) {
+	assert.Nil(t, Join())
+	assert.Nil(t, Join(nil, nil))
+
+	sentinelErr := goerrors.New("the og error")
+	err := Join(nil, BadRequest.New("name is required"), NotFound.Wrap(sentinelErr, "could not find team"))
+
+	multiErr, ok := err.(*MultiError)
+	if assert.True(t, ok) {
+		assert.Len(t, multiErr.Errs, 2)
+	}
+
+	assert.EqualError(t, err, "name is required\ncould not find team: the og error")
+	assert.True(t, Is(err, sentinelErr))
+
+	var hapiErr HapiError
+	assert.True(t, As(err, &hapiErr))
+	assert.Equal(t, BadRequest, hapiErr.ErrorType)
+}
+
+func TestMultiErrorStatusCode(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		precedence         []ErrorType
+		errs               []error
+		expectedStatusCode int
+		expectedErrorType  ErrorType
+	}{
+		{
+			desc:               "Highest status code wins by default",
+			errs:               []error{BadRequest.New("bad"), NotFound.New("not found"), Forbidden.New("forbidden")},
+			expectedStatusCode: http.StatusNotFound,
+			expectedErrorType:  NotFound,
+		},
+		{
+			desc:               "Standard errors are internal server errors",
+			errs:               []error{BadRequest.New("bad"), goerrors.New("some go error")},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedErrorType:  NoType,
+		},
+		{
+			desc:               "Precedence wins over status code",
+			precedence:         []ErrorType{Unauthorized, BadRequest},
+			errs:               []error{NotFound.New("not found"), BadRequest.New("bad"), Unauthorized.New("who are you")},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedErrorType:  Unauthorized,
+		},
+		{
+			desc:               "Error in precedence wins over error that isn't",
+			precedence:         []ErrorType{BadRequest},
+			errs:               []error{InternalServerError.New("oops"), BadRequest.New("bad")},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorType:  BadRequest,
+		},
+		{
+			desc:               "Precedence applies to nested multi errors",
+			precedence:         []ErrorType{BadRequest},
+			errs:               []error{NotFound.New("not found"), Join(Forbidden.New("forbidden"), BadRequest.New("bad"))},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedErrorType:  BadRequest,
+		},
+		{
+			desc:               "Nested multi error and standard library join",
+			errs:               []error{BadRequest.New("bad"), stderrors.Join(Forbidden.New("forbidden")), Join(NotFound.New("not found"))},
+			expectedStatusCode: http.StatusNotFound,
+			expectedErrorType:  NotFound,
+		},
+		{
+			desc:               "No errors",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedErrorType:  NoType,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			multiErr := &MultiError{Errs: tc.errs, Precedence: tc.precedence}
+
+			assert.Equal(t, tc.expectedStatusCode, multiErr.GetStatusCode())
+			assert.Equal(t, tc.expectedErrorType, multiErr.GetErrorType())
+		})
+	}
+}
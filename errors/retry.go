@@ -0,0 +1,148 @@
+package errors
+
+import (
+	"math"
+	"time"
+)
+
+type retryHint struct {
+	retryable bool
+	temporary bool
+	after     time.Duration
+}
+
+// IsRetryable reports if a request that failed with the ErrorType can be retried as is.
+// TooManyRequests, ServiceUnavailable and GatewayTimeout are retryable.
+func (errorType ErrorType) IsRetryable() bool {
+	switch errorType {
+	case TooManyRequests, ServiceUnavailable, GatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTemporary reports if the ErrorType is for a problem that should go away on its own.
+func (errorType ErrorType) IsTemporary() bool {
+	return errorType.IsRetryable()
+}
+
+// WithRetry returns new error that is retryable and temporary no matter its ErrorType. If
+// after is more than 0, it is the suggested backoff and is sent in the Retry-After header.
+func (e HapiError) WithRetry(after time.Duration) HapiError {
+	if after > 0 {
+		e = e.WithRetryAfter(after)
+	}
+
+	e.retry = &retryHint{
+		retryable: true,
+		temporary: true,
+		after:     after,
+	}
+
+	return e
+}
+
+// WithoutRetry returns new error that is not retryable or temporary no matter its ErrorType.
+func (e HapiError) WithoutRetry() HapiError {
+	e.retry = &retryHint{}
+
+	return e
+}
+
+// IsRetryable reports if the request can be retried, it is the ErrorType's unless WithRetry or
+// WithoutRetry was used.
+func (e HapiError) IsRetryable() bool {
+	if e.retry != nil {
+		return e.retry.retryable
+	}
+
+	return e.ErrorType.IsRetryable()
+}
+
+// IsTemporary reports if the problem should go away on its own, it is the ErrorType's unless
+// WithRetry or WithoutRetry was used.
+func (e HapiError) IsTemporary() bool {
+	if e.retry != nil {
+		return e.retry.temporary
+	}
+
+	return e.ErrorType.IsTemporary()
+}
+
+// GetRetryAfter gets the suggested backoff, it is 0 if there isn't one.
+func (e HapiError) GetRetryAfter() time.Duration {
+	if e.retry == nil {
+		return 0
+	}
+
+	return e.retry.after
+}
+
+// hasRetryOpinion is true when the HapiError should decide retries instead of the errors it wraps
+func (e HapiError) hasRetryOpinion() bool {
+	return e.retry != nil || e.ErrorType != NoType
+}
+
+// IsRetryable walks err's chain and reports if the request that failed with it can be retried.
+// The first HapiError with an ErrorType (or a retry hint) decides. Errors with a Temporary() bool
+// method, like net.Error, are also checked. Joined errors are only retryable if all of them are.
+func IsRetryable(err error) bool {
+	return walkRetry(err, func(e HapiError) bool { return e.IsRetryable() })
+}
+
+// IsTemporary walks err's chain and reports if it is a problem that should go away on its own,
+// the same way as IsRetryable.
+func IsTemporary(err error) bool {
+	return walkRetry(err, func(e HapiError) bool { return e.IsTemporary() })
+}
+
+// RetryAfter walks err's chain and returns the first suggested backoff it finds.
+func RetryAfter(err error) (time.Duration, bool) {
+	for err != nil {
+		hapiErr, ok := err.(HapiError)
+		if ok && hapiErr.GetRetryAfter() > 0 {
+			return hapiErr.GetRetryAfter(), true
+		}
+
+		err = Unwrap(err)
+	}
+
+	return 0, false
+}
+
+// RetryAfterSeconds rounds wait up to the second for the Retry-After header.
+func RetryAfterSeconds(wait time.Duration) int {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 0 {
+		return 0
+	}
+
+	return seconds
+}
+
+func walkRetry(err error, decide func(e HapiError) bool) bool {
+	for err != nil {
+		switch e := err.(type) {
+		case HapiError:
+			if e.hasRetryOpinion() {
+				return decide(e)
+			}
+		case interface{ Temporary() bool }:
+			return e.Temporary()
+		case interface{ Unwrap() []error }:
+			errs := e.Unwrap()
+			for _, joined := range errs {
+				if !walkRetry(joined, decide) {
+					return false
+				}
+			}
+
+			return len(errs) > 0
+		}
+
+		err = Unwrap(err)
+	}
+
+	return false
+}
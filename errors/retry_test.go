@@ -0,0 +1,162 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type temporaryError struct {
+	temporary bool
+}
+
+func (e temporaryError) Error() string {
+	return "temporary error"
+}
+
+func (e temporaryError) Temporary() bool {
+	return e.temporary
+}
+
+func TestIsRetryable(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		err               error
+		expectedRetryable bool
+		expectedTemporary bool
+	}{
+		{
+			desc:              "Retryable error type",
+			err:               ServiceUnavailable.New("down for maintenance"),
+			expectedRetryable: true,
+			expectedTemporary: true,
+		},
+		{
+			desc:              "Not retryable error type",
+			err:               BadRequest.New("name is required"),
+			expectedRetryable: false,
+			expectedTemporary: false,
+		},
+		{
+			desc:              "Retryable with retry hint",
+			err:               InternalServerError.New("deadlock").WithRetry(0),
+			expectedRetryable: true,
+			expectedTemporary: true,
+		},
+		{
+			desc:              "Not retryable with retry hint",
+			err:               TooManyRequests.New("you are banned").WithoutRetry(),
+			expectedRetryable: false,
+			expectedTemporary: false,
+		},
+		{
+			desc:              "Outer hapi error decides",
+			err:               BadRequest.Wrap(ServiceUnavailable.New("down"), "bad"),
+			expectedRetryable: false,
+			expectedTemporary: false,
+		},
+		{
+			desc:              "No type hapi error lets wrapped error decide",
+			err:               Wrap(ServiceUnavailable.New("down"), "failed to call users"),
+			expectedRetryable: true,
+			expectedTemporary: true,
+		},
+		{
+			desc:              "Temporary error in the chain",
+			err:               fmt.Errorf("failed to dial: %w", temporaryError{temporary: true}),
+			expectedRetryable: true,
+			expectedTemporary: true,
+		},
+		{
+			desc:              "Net timeout",
+			err:               goerrors.Wrap(&net.DNSError{IsTimeout: true, IsTemporary: true}, "failed to look up host"),
+			expectedRetryable: true,
+			expectedTemporary: true,
+		},
+		{
+			desc:              "Joined errors that are all retryable",
+			err:               Join(ServiceUnavailable.New("down"), TooManyRequests.New("slow down")),
+			expectedRetryable: true,
+			expectedTemporary: true,
+		},
+		{
+			desc:              "Joined errors that aren't all retryable",
+			err:               Join(ServiceUnavailable.New("down"), BadRequest.New("bad")),
+			expectedRetryable: false,
+			expectedTemporary: false,
+		},
+		{
+			desc:              "Standard error",
+			err:               context.Canceled,
+			expectedRetryable: false,
+			expectedTemporary: false,
+		},
+		{
+			desc:              "Nil",
+			err:               nil,
+			expectedRetryable: false,
+			expectedTemporary: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expectedRetryable, IsRetryable(tc.err))
+			assert.Equal(t, tc.expectedTemporary, IsTemporary(tc.err))
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		err             error
+		expectedAfter   time.Duration
+		expectedOk      bool
+		expectedHeader  string
+		expectRetryable bool
+	}{
+		{
+			desc:            "With retry",
+			err:             InternalServerError.New("deadlock").WithRetry(1500 * time.Millisecond),
+			expectedAfter:   1500 * time.Millisecond,
+			expectedOk:      true,
+			expectedHeader:  "2",
+			expectRetryable: true,
+		},
+		{
+			desc:            "With retry after keeps error type's retryable",
+			err:             TooManyRequests.New("slow down").WithRetryAfter(time.Minute),
+			expectedAfter:   time.Minute,
+			expectedOk:      true,
+			expectedHeader:  "60",
+			expectRetryable: true,
+		},
+		{
+			desc:            "Wrapped",
+			err:             Wrap(ServiceUnavailable.New("down").WithRetryAfter(time.Second), "failed to call users"),
+			expectedAfter:   time.Second,
+			expectedOk:      true,
+			expectRetryable: true,
+		},
+		{
+			desc:            "No suggested backoff",
+			err:             ServiceUnavailable.New("down"),
+			expectRetryable: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			after, ok := RetryAfter(tc.err)
+
+			assert.Equal(t, tc.expectedAfter, after)
+			assert.Equal(t, tc.expectedOk, ok)
+			assert.Equal(t, tc.expectRetryable, IsRetryable(tc.err))
+			assert.Equal(t, tc.expectedHeader, CastToHapiError(tc.err).GetHeaders().Get("Retry-After"))
+		})
+	}
+}
@@ -34,8 +34,64 @@ const (
 
 	// InternalServerError 500 error
 	InternalServerError
+
+	// GatewayTimeout 504 error
+	GatewayTimeout
+
+	// ClientClosedRequest 499 error, the client went away before we could respond
+	ClientClosedRequest
+
+	// MethodNotAllowed 405 error
+	MethodNotAllowed
+
+	// TooManyRequests 429 error
+	TooManyRequests
+
+	// ServiceUnavailable 503 error
+	ServiceUnavailable
+
+	// PreconditionFailed 412 error, e.g. If-Match didn't match the current ETag
+	PreconditionFailed
+
+	// UnsupportedMediaType 415 error, e.g. a request body with a Content-Encoding we can't decode
+	UnsupportedMediaType
 )
 
+// errorTypes is every defined ErrorType
+var errorTypes = []ErrorType{
+	NoType,
+	BadRequest,
+	Unauthorized,
+	Forbidden,
+	NotFound,
+	TooLarge,
+	ImATeapot,
+	InternalServerError,
+	GatewayTimeout,
+	ClientClosedRequest,
+	MethodNotAllowed,
+	TooManyRequests,
+	ServiceUnavailable,
+	PreconditionFailed,
+	UnsupportedMediaType,
+}
+
+// ParseErrorType gets the ErrorType from its name, the opposite of String. It returns
+// false if there is no ErrorType with the name.
+func ParseErrorType(name string) (ErrorType, bool) {
+	for _, errorType := range errorTypes {
+		if errorType.String() == name {
+			return errorType, true
+		}
+	}
+
+	return NoType, false
+}
+
+// StatusClientClosedRequest is the non standard status code nginx uses when the client
+// closes the connection before the server responds
+const StatusClientClosedRequest = 499
+
 // Newf creates a new hapiError with formatted message
 func (errorType ErrorType) Newf(format string, args ...interface{}) HapiError {
 	message := fmt.Sprintf(format, args...)
@@ -47,7 +103,8 @@ func (errorType ErrorType) Newf(format string, args ...interface{}) HapiError {
 	}
 }
 
-// New creates a new hapiError
+// New creates a new hapiError. The message is sent to the client, use Public and Internal
+// if you need details in the raw error that the client shouldn't see.
 func (errorType ErrorType) New(message string) HapiError {
 	return HapiError{
 		ErrorType: errorType,
@@ -56,6 +113,18 @@ func (errorType ErrorType) New(message string) HapiError {
 	}
 }
 
+// Public creates a new hapiError with a message that is safe to send to the client, even when
+// sanitizing server errors. Use Internal to add details that should only ever be in the raw error, e.g.
+// errors.NotFound.Public("user not found").Internal("id=%s shard=%d", id, shard)
+func (errorType ErrorType) Public(message string) HapiError {
+	return HapiError{
+		ErrorType: errorType,
+		Err:       errors.New(message),
+		Message:   message,
+		safe:      true,
+	}
+}
+
 // Wrapf creates a new wrapped hapiError with formatted message
 func (errorType ErrorType) Wrapf(err error, format string, args ...interface{}) HapiError {
 	message := fmt.Sprintf(format, args...)
@@ -81,6 +150,99 @@ func (errorType ErrorType) Cast(err error, message string) HapiError {
 	}
 }
 
+// FromStatusCode returns the ErrorType for an http status code. Status codes without their own
+// ErrorType fall back to their class, BadRequest for 4xx and InternalServerError for 5xx. Anything
+// else is NoType.
+func FromStatusCode(statusCode int) ErrorType {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return BadRequest
+	case http.StatusUnauthorized:
+		return Unauthorized
+	case http.StatusForbidden:
+		return Forbidden
+	case http.StatusNotFound:
+		return NotFound
+	case http.StatusRequestEntityTooLarge:
+		return TooLarge
+	case http.StatusTeapot:
+		return ImATeapot
+	case http.StatusInternalServerError:
+		return InternalServerError
+	case http.StatusGatewayTimeout:
+		return GatewayTimeout
+	case StatusClientClosedRequest:
+		return ClientClosedRequest
+	case http.StatusMethodNotAllowed:
+		return MethodNotAllowed
+	case http.StatusTooManyRequests:
+		return TooManyRequests
+	case http.StatusServiceUnavailable:
+		return ServiceUnavailable
+	case http.StatusPreconditionFailed:
+		return PreconditionFailed
+	case http.StatusUnsupportedMediaType:
+		return UnsupportedMediaType
+	}
+
+	switch {
+	case statusCode >= 400 && statusCode < 500:
+		return BadRequest
+	case statusCode >= 500 && statusCode < 600:
+		return InternalServerError
+	default:
+		return NoType
+	}
+}
+
+// NewFromStatus creates a new hapiError with the ErrorType for the http status code
+func NewFromStatus(statusCode int, message string) HapiError {
+	return FromStatusCode(statusCode).New(message)
+}
+
+// StatusCode returns the http status code for the ErrorType
+func (errorType ErrorType) StatusCode() int {
+	return getStatusCode(errorType)
+}
+
+// String returns the name of the ErrorType
+func (errorType ErrorType) String() string {
+	switch errorType {
+	case NoType:
+		return "NoType"
+	case BadRequest:
+		return "BadRequest"
+	case Unauthorized:
+		return "Unauthorized"
+	case Forbidden:
+		return "Forbidden"
+	case NotFound:
+		return "NotFound"
+	case TooLarge:
+		return "TooLarge"
+	case ImATeapot:
+		return "ImATeapot"
+	case InternalServerError:
+		return "InternalServerError"
+	case GatewayTimeout:
+		return "GatewayTimeout"
+	case ClientClosedRequest:
+		return "ClientClosedRequest"
+	case MethodNotAllowed:
+		return "MethodNotAllowed"
+	case TooManyRequests:
+		return "TooManyRequests"
+	case ServiceUnavailable:
+		return "ServiceUnavailable"
+	case PreconditionFailed:
+		return "PreconditionFailed"
+	case UnsupportedMediaType:
+		return "UnsupportedMediaType"
+	default:
+		return fmt.Sprintf("ErrorType(%d)", uint(errorType))
+	}
+}
+
 func getStatusCode(errorType ErrorType) int {
 	switch errorType {
 	case BadRequest:
@@ -97,6 +259,20 @@ func getStatusCode(errorType ErrorType) int {
 		return http.StatusTeapot // 418
 	case InternalServerError:
 		return http.StatusInternalServerError // 500
+	case GatewayTimeout:
+		return http.StatusGatewayTimeout // 504
+	case ClientClosedRequest:
+		return StatusClientClosedRequest // 499
+	case MethodNotAllowed:
+		return http.StatusMethodNotAllowed // 405
+	case TooManyRequests:
+		return http.StatusTooManyRequests // 429
+	case ServiceUnavailable:
+		return http.StatusServiceUnavailable // 503
+	case PreconditionFailed:
+		return http.StatusPreconditionFailed // 412
+	case UnsupportedMediaType:
+		return http.StatusUnsupportedMediaType // 415
 	default:
 		return http.StatusInternalServerError // 500
 	}
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"net/http"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -199,3 +200,125 @@ func TestHapiErrorCast(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorTypeString(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		errorType ErrorType
+		expected  string
+	}{
+		{
+			desc:      "Known error type",
+			errorType: NotFound,
+			expected:  "NotFound",
+		},
+		{
+			desc:      "Unknown error type",
+			errorType: ErrorType(0),
+			expected:  "ErrorType(0)",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.errorType.String())
+		})
+	}
+}
+
+func TestErrorTypeStatusCode(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		errorType ErrorType
+		expected  int
+	}{
+		{
+			desc:      "Known error type",
+			errorType: TooLarge,
+			expected:  http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:      "No type",
+			errorType: NoType,
+			expected:  http.StatusInternalServerError,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.errorType.StatusCode())
+		})
+	}
+}
+
+func TestFromStatusCode(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		statusCode int
+		expected   ErrorType
+	}{
+		{
+			desc:       "Unknown 4xx",
+			statusCode: http.StatusConflict,
+			expected:   BadRequest,
+		},
+		{
+			desc:       "Unknown 5xx",
+			statusCode: http.StatusBadGateway,
+			expected:   InternalServerError,
+		},
+		{
+			desc:       "2xx",
+			statusCode: http.StatusOK,
+			expected:   NoType,
+		},
+		{
+			desc:       "Not a real status code",
+			statusCode: 42,
+			expected:   NoType,
+		},
+	}
+
+	// every defined ErrorType should make it back to itself
+	for _, errorType := range errorTypes[1:] {
+		testCases = append(testCases, struct {
+			desc       string
+			statusCode int
+			expected   ErrorType
+		}{
+			desc:       errorType.String(),
+			statusCode: errorType.StatusCode(),
+			expected:   errorType,
+		})
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, FromStatusCode(tc.statusCode))
+		})
+	}
+}
+
+func TestNewFromStatus(t *testing.T) {
+	actual := NewFromStatus(http.StatusNotFound, "could not find user")
+
+	compareErrors(t, NotFound.New("could not find user"), actual)
+}
+
+func TestErrorTypePublic(t *testing.T) {
+	actual := NotFound.Public("user not found")
+
+	compareErrors(t, NotFound.New("user not found"), actual)
+}
+
+func TestParseErrorType(t *testing.T) {
+	for _, errorType := range errorTypes {
+		t.Run(errorType.String(), func(t *testing.T) {
+			actual, ok := ParseErrorType(errorType.String())
+
+			assert.True(t, ok)
+			assert.Equal(t, errorType, actual)
+		})
+	}
+
+	_, ok := ParseErrorType("NotARealErrorType")
+	assert.False(t, ok)
+}
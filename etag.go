@@ -0,0 +1,150 @@
+package hapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+// CheckPreconditions checks the If-Match, If-Unmodified-Since and If-None-Match headers of a write
+// against the current etag and lastModified of the resource, pass an empty etag if it doesn't exist
+// yet. Call it before making the change, it returns a PreconditionFailed error if the client's copy
+// is out of date so that RespondError sends a 412.
+func CheckPreconditions(r *http.Request, etag string, lastModified time.Time) error {
+	if etag != "" {
+		etag = formatETag(etag)
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch != "" {
+		if !etagMatches(ifMatch, etag, false) {
+			return errors.PreconditionFailed.New("resource has been modified")
+		}
+	} else if modifiedSince(r.Header.Get("If-Unmodified-Since"), lastModified) {
+		return errors.PreconditionFailed.New("resource has been modified")
+	}
+
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, true) {
+		return errors.PreconditionFailed.New("resource already exists")
+	}
+
+	return nil
+}
+
+// respondNotModified sets the ETag and Last-Modified headers of a successful response and writes
+// a 304 if the client's copy is still fresh. It returns true if the 304 was written.
+func respondNotModified(w http.ResponseWriter, o responseOptions, statusCode int, body []byte) bool {
+	if statusCode < 200 || statusCode >= 300 {
+		return false
+	}
+
+	etag := o.etag
+	if o.bodyETag {
+		etag = newBodyETag(body, o.weakETag)
+	}
+
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+
+	if !o.lastModified.IsZero() {
+		w.Header().Set("Last-Modified", o.lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if !o.notModified(etag) {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+
+	return true
+}
+
+// notModified checks If-None-Match and If-Modified-Since, they only apply to GET and HEAD
+func (o responseOptions) notModified(etag string) bool {
+	if o.request == nil || (o.request.Method != http.MethodGet && o.request.Method != http.MethodHead) {
+		return false
+	}
+
+	ifNoneMatch := o.request.Header.Get("If-None-Match")
+	if ifNoneMatch != "" {
+		return etagMatches(ifNoneMatch, etag, true)
+	}
+
+	ifModifiedSince := o.request.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || o.lastModified.IsZero() {
+		return false
+	}
+
+	return !modifiedSince(ifModifiedSince, o.lastModified)
+}
+
+// modifiedSince checks if lastModified is after the http date in header. It is false if either is missing.
+func modifiedSince(header string, lastModified time.Time) bool {
+	if header == "" || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+
+	// http dates don't have anything smaller than a second
+	return lastModified.Truncate(time.Second).After(since)
+}
+
+// formatETag quotes etag if it isn't already
+func formatETag(etag string) string {
+	if strings.HasPrefix(etag, `"`) || strings.HasPrefix(etag, `W/"`) {
+		return etag
+	}
+
+	return `"` + etag + `"`
+}
+
+func newBodyETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	if weak {
+		return "W/" + etag
+	}
+
+	return etag
+}
+
+// etagMatches checks if etag is in the list of ETags in header. With the weak comparison, W/"a" and
+// "a" match, with the strong one neither can be weak. * matches any etag that isn't empty.
+func etagMatches(header, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if candidate == "*" {
+			return true
+		}
+
+		if weak {
+			if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+
+			continue
+		}
+
+		if candidate == etag && !strings.HasPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
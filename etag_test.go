@@ -0,0 +1,257 @@
+package hapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+func TestRespondETag(t *testing.T) {
+	lastModified := time.Date(2020, time.March, 1, 12, 30, 0, 0, time.UTC)
+	bodyETag := newBodyETag([]byte(`{"name":"gopher"}`), false)
+
+	testCases := []struct {
+		desc                 string
+		method               string
+		statusCode           int
+		requestHeaders       map[string]string
+		opts                 []ResponseOption
+		expectedStatusCode   int
+		expectedBody         string
+		expectedETag         string
+		expectedLastModified string
+	}{
+		{
+			desc:               "etag from caller",
+			opts:               []ResponseOption{WithETag("v42")},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"name":"gopher"}`,
+			expectedETag:       `"v42"`,
+		},
+		{
+			desc:               "strong etag from body",
+			opts:               []ResponseOption{WithBodyETag(false)},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"name":"gopher"}`,
+			expectedETag:       bodyETag,
+		},
+		{
+			desc:               "weak etag from body",
+			opts:               []ResponseOption{WithBodyETag(true)},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"name":"gopher"}`,
+			expectedETag:       "W/" + bodyETag,
+		},
+		{
+			desc:               "if none match hits",
+			requestHeaders:     map[string]string{"If-None-Match": `"v41", "v42"`},
+			opts:               []ResponseOption{WithETag("v42")},
+			expectedStatusCode: http.StatusNotModified,
+			expectedETag:       `"v42"`,
+		},
+		{
+			desc:               "if none match uses weak comparison",
+			requestHeaders:     map[string]string{"If-None-Match": `W/"v42"`},
+			opts:               []ResponseOption{WithETag("v42")},
+			expectedStatusCode: http.StatusNotModified,
+			expectedETag:       `"v42"`,
+		},
+		{
+			desc:               "if none match with body etag",
+			requestHeaders:     map[string]string{"If-None-Match": bodyETag},
+			opts:               []ResponseOption{WithBodyETag(false)},
+			expectedStatusCode: http.StatusNotModified,
+			expectedETag:       bodyETag,
+		},
+		{
+			desc:               "if none match misses",
+			requestHeaders:     map[string]string{"If-None-Match": `"v41"`},
+			opts:               []ResponseOption{WithETag("v42")},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"name":"gopher"}`,
+			expectedETag:       `"v42"`,
+		},
+		{
+			desc:               "if none match is ignored for writes",
+			method:             http.MethodPut,
+			requestHeaders:     map[string]string{"If-None-Match": `"v42"`},
+			opts:               []ResponseOption{WithETag("v42")},
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"name":"gopher"}`,
+			expectedETag:       `"v42"`,
+		},
+		{
+			desc:               "error responses don't get an etag",
+			statusCode:         http.StatusNotFound,
+			requestHeaders:     map[string]string{"If-None-Match": `"v42"`},
+			opts:               []ResponseOption{WithETag("v42")},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `{"name":"gopher"}`,
+		},
+		{
+			desc:                 "not modified since",
+			requestHeaders:       map[string]string{"If-Modified-Since": "Sun, 01 Mar 2020 12:30:00 GMT"},
+			opts:                 []ResponseOption{WithLastModified(lastModified.Add(500 * time.Millisecond))},
+			expectedStatusCode:   http.StatusNotModified,
+			expectedLastModified: "Sun, 01 Mar 2020 12:30:00 GMT",
+		},
+		{
+			desc:                 "modified since",
+			requestHeaders:       map[string]string{"If-Modified-Since": "Sun, 01 Mar 2020 12:29:59 GMT"},
+			opts:                 []ResponseOption{WithLastModified(lastModified)},
+			expectedStatusCode:   http.StatusOK,
+			expectedBody:         `{"name":"gopher"}`,
+			expectedLastModified: "Sun, 01 Mar 2020 12:30:00 GMT",
+		},
+		{
+			desc: "if none match wins over if modified since",
+			requestHeaders: map[string]string{
+				"If-None-Match":     `"v41"`,
+				"If-Modified-Since": "Sun, 01 Mar 2020 12:30:00 GMT",
+			},
+			opts:                 []ResponseOption{WithETag("v42"), WithLastModified(lastModified)},
+			expectedStatusCode:   http.StatusOK,
+			expectedBody:         `{"name":"gopher"}`,
+			expectedETag:         `"v42"`,
+			expectedLastModified: "Sun, 01 Mar 2020 12:30:00 GMT",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			method := tc.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			statusCode := tc.statusCode
+			if statusCode == 0 {
+				statusCode = http.StatusOK
+			}
+
+			req := httptest.NewRequest(method, "/users/1", nil)
+			for key, value := range tc.requestHeaders {
+				req.Header.Set(key, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			err := Respond(recorder, statusCode, map[string]string{"name": "gopher"}, append(tc.opts, WithRequest(req))...)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			assert.Equal(t, tc.expectedBody, recorder.Body.String())
+			assert.Equal(t, tc.expectedETag, recorder.Header().Get("ETag"))
+			assert.Equal(t, tc.expectedLastModified, recorder.Header().Get("Last-Modified"))
+		})
+	}
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	lastModified := time.Date(2020, time.March, 1, 12, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc           string
+		requestHeaders map[string]string
+		etag           string
+		lastModified   time.Time
+		expectedErr    bool
+	}{
+		{
+			desc: "no preconditions",
+			etag: "v42",
+		},
+		{
+			desc:           "if match hits",
+			requestHeaders: map[string]string{"If-Match": `"v41", "v42"`},
+			etag:           "v42",
+		},
+		{
+			desc:           "if match misses",
+			requestHeaders: map[string]string{"If-Match": `"v41"`},
+			etag:           "v42",
+			expectedErr:    true,
+		},
+		{
+			desc:           "if match uses strong comparison",
+			requestHeaders: map[string]string{"If-Match": `W/"v42"`},
+			etag:           `W/"v42"`,
+			expectedErr:    true,
+		},
+		{
+			desc:           "if match any",
+			requestHeaders: map[string]string{"If-Match": "*"},
+			etag:           "v42",
+		},
+		{
+			desc:           "if match any when the resource doesn't exist",
+			requestHeaders: map[string]string{"If-Match": "*"},
+			expectedErr:    true,
+		},
+		{
+			desc:           "if none match any when the resource doesn't exist",
+			requestHeaders: map[string]string{"If-None-Match": "*"},
+		},
+		{
+			desc:           "if none match any when the resource exists",
+			requestHeaders: map[string]string{"If-None-Match": "*"},
+			etag:           "v42",
+			expectedErr:    true,
+		},
+		{
+			desc:           "not modified since",
+			requestHeaders: map[string]string{"If-Unmodified-Since": "Sun, 01 Mar 2020 12:30:00 GMT"},
+			lastModified:   lastModified,
+		},
+		{
+			desc:           "modified since",
+			requestHeaders: map[string]string{"If-Unmodified-Since": "Sun, 01 Mar 2020 12:29:59 GMT"},
+			lastModified:   lastModified,
+			expectedErr:    true,
+		},
+		{
+			desc: "if match wins over if unmodified since",
+			requestHeaders: map[string]string{
+				"If-Match":            `"v42"`,
+				"If-Unmodified-Since": "Sun, 01 Mar 2020 12:29:59 GMT",
+			},
+			etag:         "v42",
+			lastModified: lastModified,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/users/1", nil)
+			for key, value := range tc.requestHeaders {
+				req.Header.Set(key, value)
+			}
+
+			err := CheckPreconditions(req, tc.etag, tc.lastModified)
+
+			if !tc.expectedErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			var hapiErr errors.HapiError
+			if assert.True(t, errors.As(err, &hapiErr)) {
+				assert.Equal(t, errors.PreconditionFailed, hapiErr.ErrorType)
+				assert.Equal(t, http.StatusPreconditionFailed, hapiErr.GetStatusCode())
+			}
+		})
+	}
+}
+
+func TestRespondErrorPreconditionFailed(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPut, "/users/1", nil)
+	req.Header.Set("If-Match", `"v41"`)
+
+	recorder := httptest.NewRecorder()
+	err := RespondError(recorder, CheckPreconditions(req, "v42", time.Time{}), WithRequest(req))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+	assert.JSONEq(t, `{"error":"resource has been modified"}`, recorder.Body.String())
+}
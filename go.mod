@@ -1,9 +1,15 @@
 module github.com/thestephenstanton/hapi
 
-go 1.13
+go 1.23
 
 require (
-	github.com/gorilla/mux v1.7.3 // indirect
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.4.0
+	gopkg.in/yaml.v2 v2.2.2
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/gorilla/mux v1.7.3 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 )
@@ -0,0 +1,19 @@
+module github.com/thestephenstanton/hapi/hapigrpc
+
+go 1.23.0
+
+require (
+	github.com/pkg/errors v0.9.1
+	github.com/stretchr/testify v1.4.0
+	github.com/thestephenstanton/hapi v0.0.0-20261018174056-476a76f90378
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250825161204-c5933d9347a5
+	google.golang.org/grpc v1.75.1
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
@@ -0,0 +1,209 @@
+// Package hapigrpc converts between hapi errors and gRPC statuses so errors can cross from gRPC
+// backends to an http edge and back. It lives in its own module so hapi doesn't depend on gRPC
+// unless you use it.
+package hapigrpc
+
+import (
+	"net/http"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/thestephenstanton/hapi"
+	"github.com/thestephenstanton/hapi/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorInfoDomain is the domain of the errdetails.ErrorInfo detail that carries the ErrorType
+// so that it survives a round trip even when two ErrorTypes share a code
+const ErrorInfoDomain = "hapi"
+
+// ToCode gets the gRPC code for an ErrorType
+func ToCode(errorType errors.ErrorType) codes.Code {
+	switch errorType {
+	case errors.BadRequest, errors.UnsupportedMediaType:
+		return codes.InvalidArgument
+	case errors.Unauthorized:
+		return codes.Unauthenticated
+	case errors.Forbidden:
+		return codes.PermissionDenied
+	case errors.NotFound:
+		return codes.NotFound
+	case errors.TooLarge, errors.TooManyRequests:
+		return codes.ResourceExhausted
+	case errors.InternalServerError:
+		return codes.Internal
+	case errors.GatewayTimeout:
+		return codes.DeadlineExceeded
+	case errors.ClientClosedRequest:
+		return codes.Canceled
+	case errors.MethodNotAllowed:
+		return codes.Unimplemented
+	case errors.ServiceUnavailable:
+		return codes.Unavailable
+	case errors.PreconditionFailed:
+		return codes.FailedPrecondition
+	default:
+		return codes.Unknown
+	}
+}
+
+// FromCode gets the ErrorType for a gRPC code. Codes without their own ErrorType use the
+// ErrorType for the http status code google maps them to.
+func FromCode(code codes.Code) errors.ErrorType {
+	switch code {
+	case codes.OK:
+		return errors.NoType
+	case codes.Canceled:
+		return errors.ClientClosedRequest
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange, codes.AlreadyExists, codes.Aborted:
+		return errors.BadRequest
+	case codes.DeadlineExceeded:
+		return errors.GatewayTimeout
+	case codes.NotFound:
+		return errors.NotFound
+	case codes.PermissionDenied:
+		return errors.Forbidden
+	case codes.ResourceExhausted:
+		return errors.TooManyRequests
+	case codes.Unauthenticated:
+		return errors.Unauthorized
+	case codes.Unavailable:
+		return errors.ServiceUnavailable
+	default:
+		return errors.InternalServerError
+	}
+}
+
+// ToStatus turns err into a gRPC status. HapiErrors keep their message for the client, their
+// ErrorType in an errdetails.ErrorInfo and their suggested backoff in an errdetails.RetryInfo.
+// Errors that aren't HapiErrors become Unknown with hapi.Config.DefaultErrorMessage, the same
+// as RespondError, so the raw error never reaches the client. MultiErrors and joined errors get
+// the code of the error that wins by precedence, like the status code RespondError picks.
+func ToStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	errorType, statusCode, message := clientError(err)
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+
+	st := status.New(ToCode(errorType), message)
+
+	details := []protoadapt.MessageV1{
+		&errdetails.ErrorInfo{
+			Reason: errorType.String(),
+			Domain: ErrorInfoDomain,
+		},
+	}
+
+	retryAfter, ok := errors.RetryAfter(err)
+	if ok {
+		details = append(details, &errdetails.RetryInfo{
+			RetryDelay: durationpb.New(retryAfter),
+		})
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st
+	}
+
+	return withDetails
+}
+
+// clientError gets the ErrorType, status code and message for the client the same way RespondError
+// does, MultiErrors and joined errors use the error that wins by their precedence
+func clientError(err error) (errors.ErrorType, int, string) {
+	var multiErr *errors.MultiError
+	switch e := err.(type) {
+	case *errors.MultiError:
+		multiErr = &errors.MultiError{
+			Errs:       e.Errs,
+			Message:    e.Message,
+			Precedence: e.Precedence,
+		}
+	case interface{ Unwrap() []error }:
+		multiErr = &errors.MultiError{
+			Errs: e.Unwrap(),
+		}
+	}
+
+	if multiErr != nil {
+		if multiErr.Precedence == nil {
+			multiErr.Precedence = hapi.Config.MultiErrorPrecedence
+		}
+
+		return multiErr.GetErrorType(), multiErr.GetStatusCode(), multiErr.GetMessage()
+	}
+
+	hapiErr := errors.CastToHapiError(err)
+
+	// only HapiErrors have a message that is meant for the client
+	if !errors.As(err, &hapiErr) {
+		return hapiErr.ErrorType, hapiErr.GetStatusCode(), hapi.Config.DefaultErrorMessage
+	}
+
+	return hapiErr.ErrorType, hapiErr.GetStatusCode(), hapiErr.GetMessage()
+}
+
+// ToError turns err into a gRPC status error that can be returned from a gRPC handler.
+func ToError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return ToStatus(err).Err()
+}
+
+// FromStatus turns a gRPC status into a HapiError. The ErrorType comes from the hapi
+// errdetails.ErrorInfo if there is one, otherwise from the code. An OK status has no error
+// so it becomes a NoType HapiError with an empty message, check st.Code() first if that matters.
+func FromStatus(st *status.Status) errors.HapiError {
+	errorType := FromCode(st.Code())
+
+	hapiErr := errors.HapiError{}
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			if d.GetDomain() == ErrorInfoDomain {
+				parsed, ok := errors.ParseErrorType(d.GetReason())
+				if ok {
+					errorType = parsed
+				}
+			}
+		case *errdetails.RetryInfo:
+			hapiErr = hapiErr.WithRetryAfter(d.GetRetryDelay().AsDuration())
+		}
+	}
+
+	hapiErr.ErrorType = errorType
+	hapiErr.Err = st.Err()
+	hapiErr.Message = st.Message()
+
+	// st.Err() is nil for OK but a HapiError always needs an Err
+	if hapiErr.Err == nil {
+		hapiErr.Err = goerrors.New(st.Message())
+	}
+
+	return hapiErr
+}
+
+// FromError turns a gRPC status error into a HapiError. If err is nil or has no gRPC
+// status, it is returned as is.
+func FromError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	return FromStatus(st)
+}
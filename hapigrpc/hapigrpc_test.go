@@ -0,0 +1,170 @@
+package hapigrpc
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi"
+	"github.com/thestephenstanton/hapi/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCodes(t *testing.T) {
+	testCases := []struct {
+		desc      string
+		errorType errors.ErrorType
+		code      codes.Code
+	}{
+		{desc: "NotFound", errorType: errors.NotFound, code: codes.NotFound},
+		{desc: "BadRequest", errorType: errors.BadRequest, code: codes.InvalidArgument},
+		{desc: "Unauthorized", errorType: errors.Unauthorized, code: codes.Unauthenticated},
+		{desc: "Forbidden", errorType: errors.Forbidden, code: codes.PermissionDenied},
+		{desc: "InternalServerError", errorType: errors.InternalServerError, code: codes.Internal},
+		{desc: "GatewayTimeout", errorType: errors.GatewayTimeout, code: codes.DeadlineExceeded},
+		{desc: "ClientClosedRequest", errorType: errors.ClientClosedRequest, code: codes.Canceled},
+		{desc: "TooManyRequests", errorType: errors.TooManyRequests, code: codes.ResourceExhausted},
+		{desc: "ServiceUnavailable", errorType: errors.ServiceUnavailable, code: codes.Unavailable},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.code, ToCode(tc.errorType))
+			assert.Equal(t, tc.errorType, FromCode(tc.code))
+		})
+	}
+}
+
+func TestFromCodeWithoutErrorType(t *testing.T) {
+	assert.Equal(t, errors.BadRequest, FromCode(codes.FailedPrecondition))
+	assert.Equal(t, errors.InternalServerError, FromCode(codes.DataLoss))
+	assert.Equal(t, errors.InternalServerError, FromCode(codes.Unknown))
+}
+
+func TestRoundTrip(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		err                error
+		expectedCode       codes.Code
+		expectedMessage    string
+		expectedErrorType  errors.ErrorType
+		expectedRetryAfter time.Duration
+	}{
+		{
+			desc:              "hapi error",
+			err:               errors.NotFound.Public("user not found").Internal("id=42"),
+			expectedCode:      codes.NotFound,
+			expectedMessage:   "user not found",
+			expectedErrorType: errors.NotFound,
+		},
+		{
+			desc:              "error type that shares a code survives",
+			err:               errors.TooLarge.New("body is too big"),
+			expectedCode:      codes.ResourceExhausted,
+			expectedMessage:   "body is too big",
+			expectedErrorType: errors.TooLarge,
+		},
+		{
+			desc:              "error type without its own code survives",
+			err:               errors.PreconditionFailed.New("user has been modified"),
+			expectedCode:      codes.FailedPrecondition,
+			expectedMessage:   "user has been modified",
+			expectedErrorType: errors.PreconditionFailed,
+		},
+		{
+			desc:               "retry after",
+			err:                errors.ServiceUnavailable.New("down for maintenance").WithRetryAfter(30 * time.Second),
+			expectedCode:       codes.Unavailable,
+			expectedMessage:    "down for maintenance",
+			expectedErrorType:  errors.ServiceUnavailable,
+			expectedRetryAfter: 30 * time.Second,
+		},
+		{
+			desc:              "standard error doesn't leak",
+			err:               goerrors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			expectedCode:      codes.Unknown,
+			expectedMessage:   hapi.Config.DefaultErrorMessage,
+			expectedErrorType: errors.NoType,
+		},
+		{
+			desc:              "hapi error without a message",
+			err:               errors.NotFound.Cast(goerrors.New("sql: no rows in result set"), ""),
+			expectedCode:      codes.NotFound,
+			expectedMessage:   "Not Found",
+			expectedErrorType: errors.NotFound,
+		},
+		{
+			desc:              "multi error uses the error that wins",
+			err:               errors.Join(errors.BadRequest.New("bad"), errors.InternalServerError.New("oops")),
+			expectedCode:      codes.Internal,
+			expectedMessage:   "Internal Server Error",
+			expectedErrorType: errors.InternalServerError,
+		},
+		{
+			desc: "multi error keeps its message",
+			err: &errors.MultiError{
+				Errs:       []error{errors.NotFound.New("could not find team"), errors.BadRequest.New("name is required")},
+				Message:    "could not create user",
+				Precedence: []errors.ErrorType{errors.BadRequest},
+			},
+			expectedCode:      codes.InvalidArgument,
+			expectedMessage:   "could not create user",
+			expectedErrorType: errors.BadRequest,
+		},
+		{
+			desc:              "standard library join",
+			err:               stderrors.Join(errors.BadRequest.New("bad"), errors.NotFound.New("user not found")),
+			expectedCode:      codes.NotFound,
+			expectedMessage:   "Not Found",
+			expectedErrorType: errors.NotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			grpcErr := ToError(tc.err)
+
+			st, ok := status.FromError(grpcErr)
+			if !assert.True(t, ok) {
+				return
+			}
+
+			assert.Equal(t, tc.expectedCode, st.Code())
+			assert.Equal(t, tc.expectedMessage, st.Message())
+
+			hapiErr := errors.CastToHapiError(FromError(grpcErr))
+
+			assert.Equal(t, tc.expectedErrorType, hapiErr.ErrorType)
+			assert.Equal(t, tc.expectedMessage, hapiErr.GetMessage())
+			assert.Equal(t, tc.expectedRetryAfter, hapiErr.GetRetryAfter())
+		})
+	}
+}
+
+func TestFromStatusWithoutDetails(t *testing.T) {
+	hapiErr := FromStatus(status.New(codes.PermissionDenied, "you can't see this user"))
+
+	assert.Equal(t, errors.Forbidden, hapiErr.ErrorType)
+	assert.Equal(t, "you can't see this user", hapiErr.GetMessage())
+	assert.EqualError(t, hapiErr, "rpc error: code = PermissionDenied desc = you can't see this user")
+}
+
+func TestNil(t *testing.T) {
+	assert.Nil(t, ToError(nil))
+	assert.Nil(t, FromError(nil))
+	assert.Equal(t, codes.OK, ToStatus(nil).Code())
+}
+
+func TestFromStatusOK(t *testing.T) {
+	hapiErr := FromStatus(ToStatus(nil))
+
+	assert.Equal(t, errors.NoType, hapiErr.ErrorType)
+	assert.EqualError(t, hapiErr, "")
+}
+
+func TestFromErrorWithoutStatus(t *testing.T) {
+	err := goerrors.New("not a grpc error")
+
+	assert.Equal(t, err, FromError(err))
+}
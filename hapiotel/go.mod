@@ -0,0 +1,19 @@
+module github.com/thestephenstanton/hapi/hapiotel
+
+go 1.23.0
+
+require (
+	github.com/stretchr/testify v1.12.1
+	github.com/thestephenstanton/hapi v0.0.0-20261018173804-98955bceb8ae
+	go.opentelemetry.io/otel v1.38.0
+	go.opentelemetry.io/otel/trace v1.38.0
+)
+
+require (
+	github.com/kr/pretty v0.3.1 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/rogpeppe/go-internal v1.13.1 // indirect
+	go.yaml.in/yaml/v3 v3.0.5 // indirect
+	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
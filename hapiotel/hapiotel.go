@@ -0,0 +1,37 @@
+// Package hapiotel records hapi error responses on OpenTelemetry spans. It lives in its own
+// module so hapi doesn't depend on OpenTelemetry unless you use it.
+package hapiotel
+
+import (
+	"context"
+
+	"github.com/thestephenstanton/hapi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrorHook creates a hapi.ErrorHook that marks the span in the request context with an error status,
+// records the ErrorType and status code as attributes and records the raw error as a span event.
+// Give the request to RespondError with hapi.WithRequest so the span can be found.
+//
+//	hapi.Config.ErrorHooks = append(hapi.Config.ErrorHooks, hapiotel.ErrorHook())
+func ErrorHook() hapi.ErrorHook {
+	return func(ctx context.Context, entry hapi.ErrorEntry) {
+		span := trace.SpanFromContext(ctx)
+		if !span.IsRecording() {
+			return
+		}
+
+		span.SetAttributes(
+			attribute.Int("http.response.status_code", entry.StatusCode),
+			attribute.String("hapi.error_type", entry.ErrorType.String()),
+		)
+
+		if entry.Err != nil {
+			span.RecordError(entry.Err)
+		}
+
+		span.SetStatus(codes.Error, entry.Message)
+	}
+}
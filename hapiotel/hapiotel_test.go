@@ -0,0 +1,76 @@
+package hapiotel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi"
+	"github.com/thestephenstanton/hapi/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingSpan keeps everything the hook does to it so we can assert on it
+type recordingSpan struct {
+	noop.Span
+
+	attributes        []attribute.KeyValue
+	recordedErrors    []error
+	statusCode        codes.Code
+	statusDescription string
+}
+
+func (s *recordingSpan) IsRecording() bool {
+	return true
+}
+
+func (s *recordingSpan) SetAttributes(kv ...attribute.KeyValue) {
+	s.attributes = append(s.attributes, kv...)
+}
+
+func (s *recordingSpan) RecordError(err error, options ...trace.EventOption) {
+	s.recordedErrors = append(s.recordedErrors, err)
+}
+
+func (s *recordingSpan) SetStatus(code codes.Code, description string) {
+	s.statusCode = code
+	s.statusDescription = description
+}
+
+func TestErrorHook(t *testing.T) {
+	originalHooks := hapi.Config.ErrorHooks
+	defer func() { hapi.Config.ErrorHooks = originalHooks }()
+
+	hapi.Config.ErrorHooks = []hapi.ErrorHook{ErrorHook()}
+
+	span := &recordingSpan{}
+	ctx := trace.ContextWithSpan(context.Background(), span)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hapiErr := errors.NotFound.New("could not find user")
+
+	err = hapi.RespondError(httptest.NewRecorder(), hapiErr, hapi.WithRequest(req))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, codes.Error, span.statusCode)
+	assert.Equal(t, "could not find user", span.statusDescription)
+	assert.Contains(t, span.attributes, attribute.Int("http.response.status_code", http.StatusNotFound))
+	assert.Contains(t, span.attributes, attribute.String("hapi.error_type", "NotFound"))
+	assert.Equal(t, []error{hapiErr}, span.recordedErrors)
+}
+
+func TestErrorHookWithoutSpan(t *testing.T) {
+	// there is no span in the context so this should do nothing
+	ErrorHook()(context.Background(), hapi.ErrorEntry{StatusCode: http.StatusInternalServerError})
+}
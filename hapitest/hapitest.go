@@ -0,0 +1,175 @@
+// Package hapitest has helpers for testing handlers that respond with hapi.
+package hapitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+// Response is the recorded response of a handler. Every Assert method returns the
+// Response so they can be chained.
+type Response struct {
+	*httptest.ResponseRecorder
+
+	t testing.TB
+}
+
+// Serve calls handler with the request and records the response
+func Serve(t testing.TB, handler http.Handler, request *http.Request) *Response {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	return &Response{
+		ResponseRecorder: recorder,
+		t:                t,
+	}
+}
+
+// Do creates a request with the method and target and calls handler with it. If body is not nil,
+// it will be marshalled to json and sent as the request body.
+func Do(t testing.TB, handler http.Handler, method, target string, body interface{}) *Response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to marshal request body: %v", err)
+		}
+
+		reader = bytes.NewReader(bodyBytes)
+	}
+
+	request := httptest.NewRequest(method, target, reader)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	return Serve(t, handler, request)
+}
+
+// AssertStatus asserts the status code of the response
+func (r *Response) AssertStatus(statusCode int) *Response {
+	r.t.Helper()
+
+	assert.Equal(r.t, statusCode, r.Code, "unexpected status code, body: %s", r.Body.String())
+
+	return r
+}
+
+// AssertHeader asserts the value of a response header
+func (r *Response) AssertHeader(key, value string) *Response {
+	r.t.Helper()
+
+	assert.Equal(r.t, value, r.Header().Get(key), "unexpected value for header %s", key)
+
+	return r
+}
+
+// AssertJSON asserts the body is the same json as expected. ignoredFields are removed from
+// both before comparing, use dots for nested fields (e.g. "user.createdAt"). Fields in arrays
+// are removed from every element of the array.
+func (r *Response) AssertJSON(expected string, ignoredFields ...string) *Response {
+	r.t.Helper()
+
+	expectedJSON, err := withoutFields([]byte(expected), ignoredFields)
+	if err != nil {
+		r.t.Fatalf("expected is not proper json: %v", err)
+	}
+
+	actualJSON, err := withoutFields(r.Body.Bytes(), ignoredFields)
+	if err != nil {
+		assert.Fail(r.t, "response body is not proper json", "error: %v, body: %s", err, r.Body.String())
+		return r
+	}
+
+	assert.JSONEq(r.t, string(expectedJSON), string(actualJSON))
+
+	return r
+}
+
+// Decode unmarshals the response body into v
+func (r *Response) Decode(v interface{}) {
+	r.t.Helper()
+
+	err := json.Unmarshal(r.Body.Bytes(), v)
+	if err != nil {
+		r.t.Fatalf("failed to unmarshal response body %q: %v", r.Body.String(), err)
+	}
+}
+
+// ErrorResponse decodes the response body into a hapi.ErrorResponse
+func (r *Response) ErrorResponse() hapi.ErrorResponse {
+	r.t.Helper()
+
+	var errorResponse hapi.ErrorResponse
+	r.Decode(&errorResponse)
+
+	return errorResponse
+}
+
+// AssertErrorType asserts that the response is an error response that came from a HapiError
+// of the given ErrorType. The ErrorType is only in the response when hapi.Config.ReturnErrorType
+// is on, so turn it on in your tests.
+func (r *Response) AssertErrorType(errorType errors.ErrorType) *Response {
+	r.t.Helper()
+
+	assert.Equal(r.t, errorType.StatusCode(), r.Code, "status code doesn't match %s, body: %s", errorType, r.Body.String())
+
+	var errorResponse hapi.ErrorResponse
+	err := json.Unmarshal(r.Body.Bytes(), &errorResponse)
+	if err != nil || errorResponse.ErrorMessage == "" {
+		assert.Fail(r.t, "response body is not a hapi error response", "body: %s", r.Body.String())
+		return r
+	}
+
+	if errorResponse.ErrorType == "" {
+		assert.Fail(r.t, "response body has no error type, turn on hapi.Config.ReturnErrorType", "body: %s", r.Body.String())
+		return r
+	}
+
+	assert.Equal(r.t, errorType.String(), errorResponse.ErrorType, "error type doesn't match, body: %s", r.Body.String())
+
+	return r
+}
+
+func withoutFields(data []byte, fields []string) ([]byte, error) {
+	var v interface{}
+	err := json.Unmarshal(data, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		removeField(v, strings.Split(field, "."))
+	}
+
+	return json.Marshal(v)
+}
+
+func removeField(v interface{}, path []string) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			delete(value, path[0])
+			return
+		}
+
+		removeField(value[path[0]], path[1:])
+	case []interface{}:
+		for _, element := range value {
+			removeField(element, path)
+		}
+	}
+}
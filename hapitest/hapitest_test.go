@@ -0,0 +1,164 @@
+package hapitest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+// fakeT records failures instead of failing the real test
+type fakeT struct {
+	testing.TB
+
+	failed bool
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...interface{}) {
+	f.failed = true
+}
+
+func (f *fakeT) Fatalf(format string, args ...interface{}) {
+	panic(fmt.Sprintf(format, args...))
+}
+
+var userHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("id") == "0" {
+		_ = hapi.RespondError(w, errors.InternalServerError.New("failed to get user"))
+		return
+	}
+
+	if r.URL.Query().Get("id") != "42" {
+		_ = hapi.RespondError(w, errors.NotFound.New("could not find user"))
+		return
+	}
+
+	w.Header().Set("X-Custom", "custom")
+
+	_ = hapi.RespondOK(w, map[string]interface{}{
+		"id":        "42",
+		"createdAt": "2026-10-18T00:00:00Z",
+		"friends": []map[string]interface{}{
+			{"id": "7", "createdAt": "2026-10-17T00:00:00Z"},
+		},
+	})
+})
+
+func TestAssertions(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		target       string
+		assert       func(r *Response)
+		expectFailed bool
+	}{
+		{
+			desc:   "status, header and json match",
+			target: "/users?id=42",
+			assert: func(r *Response) {
+				r.AssertStatus(http.StatusOK).
+					AssertHeader("X-Custom", "custom").
+					AssertJSON(`{"id":"42","friends":[{"id":"7"}]}`, "createdAt", "friends.createdAt")
+			},
+		},
+		{
+			desc:   "status doesn't match",
+			target: "/users?id=42",
+			assert: func(r *Response) {
+				r.AssertStatus(http.StatusCreated)
+			},
+			expectFailed: true,
+		},
+		{
+			desc:   "json doesn't match without ignored fields",
+			target: "/users?id=42",
+			assert: func(r *Response) {
+				r.AssertJSON(`{"id":"42","friends":[{"id":"7"}]}`)
+			},
+			expectFailed: true,
+		},
+		{
+			desc:   "error type matches",
+			target: "/users?id=7",
+			assert: func(r *Response) {
+				r.AssertErrorType(errors.NotFound)
+			},
+		},
+		{
+			desc:   "error type doesn't match",
+			target: "/users?id=7",
+			assert: func(r *Response) {
+				r.AssertErrorType(errors.Forbidden)
+			},
+			expectFailed: true,
+		},
+		{
+			desc:   "error type with the same status code doesn't match",
+			target: "/users?id=0",
+			assert: func(r *Response) {
+				r.AssertErrorType(errors.NoType)
+			},
+			expectFailed: true,
+		},
+		{
+			desc:   "error type on a non error response",
+			target: "/users?id=42",
+			assert: func(r *Response) {
+				r.AssertErrorType(errors.NoType)
+			},
+			expectFailed: true,
+		},
+	}
+	originalConfig := hapi.Config
+	defer func() { hapi.Config = originalConfig }()
+
+	hapi.Config.ReturnErrorType = true
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			fake := &fakeT{TB: t}
+
+			tc.assert(Do(fake, userHandler, http.MethodGet, tc.target, nil))
+
+			assert.Equal(t, tc.expectFailed, fake.failed)
+		})
+	}
+}
+
+func TestAssertErrorTypeWithoutErrorType(t *testing.T) {
+	fake := &fakeT{TB: t}
+
+	Do(fake, userHandler, http.MethodGet, "/users?id=7", nil).AssertErrorType(errors.NotFound)
+
+	assert.True(t, fake.failed)
+}
+
+func TestErrorResponse(t *testing.T) {
+	errorResponse := Do(t, userHandler, http.MethodGet, "/users?id=7", nil).
+		AssertStatus(http.StatusNotFound).
+		ErrorResponse()
+
+	assert.Equal(t, hapi.NewErrorResponse("could not find user"), errorResponse)
+}
+
+func TestDoWithBody(t *testing.T) {
+	echoHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		err := hapi.UnmarshalBody(r, &body)
+		if err != nil {
+			_ = hapi.RespondError(w, err)
+			return
+		}
+
+		_ = hapi.RespondOK(w, body)
+	})
+
+	Do(t, echoHandler, http.MethodPost, "/echo", map[string]string{"hello": "world"}).
+		AssertStatus(http.StatusOK).
+		AssertHeader("Content-Type", "application/json").
+		AssertJSON(`{"hello":"world"}`)
+}
@@ -1,8 +1,14 @@
 package hapi
 
 import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"encoding/json"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/thestephenstanton/hapi/errors"
 )
@@ -18,13 +24,20 @@ func GetQueryParam(request *http.Request, key string) (string, bool) {
 	return value, true
 }
 
-// UnmarshalBody will unmarshal the request's body into the interface provided
+// UnmarshalBody will unmarshal the request's body into the interface provided. Bodies with a gzip or
+// deflate Content-Encoding are decompressed first so WithMaxSize limits the decompressed size, any
+// other encoding is an UnsupportedMediaType error.
 func UnmarshalBody(request *http.Request, v interface{}, opts ...UnmarshalOption) error {
+	err := decodeContentEncoding(request)
+	if err != nil {
+		return err
+	}
+
 	for _, opt := range opts {
 		opt(request)
 	}
 
-	err := json.NewDecoder(request.Body).Decode(&v)
+	err = json.NewDecoder(request.Body).Decode(&v)
 	if err != nil {
 		if len(opts) > 0 && err.Error() == requestBodyTooLargeError {
 			return errors.TooLarge.Wrap(err, "request body is too large")
@@ -47,3 +60,75 @@ func WithMaxSize(w http.ResponseWriter, maxBytes int64) UnmarshalOption {
 		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
 	}
 }
+
+// supportedContentEncodings is sent back in Accept-Encoding when the request's Content-Encoding isn't one of them
+const supportedContentEncodings = "gzip, deflate"
+
+// decodeContentEncoding replaces the request's body with one that decompresses it, encodings are
+// undone in the opposite order they were applied in
+func decodeContentEncoding(request *http.Request) error {
+	contentEncoding := request.Header.Get("Content-Encoding")
+	if contentEncoding == "" {
+		return nil
+	}
+
+	encodings := strings.Split(contentEncoding, ",")
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+
+		var reader io.Reader
+		var err error
+
+		switch encoding {
+		case "identity", "":
+			continue
+		case "gzip", "x-gzip":
+			reader, err = gzip.NewReader(request.Body)
+		case "deflate":
+			reader, err = newDeflateReader(request.Body)
+		default:
+			return errors.UnsupportedMediaType.
+				Newf("content encoding %s is not supported", encoding).
+				WithHeader("Accept-Encoding", supportedContentEncodings)
+		}
+
+		if err != nil {
+			return errors.BadRequest.Wrapf(err, "request body is not proper %s", encoding)
+		}
+
+		request.Body = readCloser{
+			Reader: reader,
+			Closer: request.Body,
+		}
+	}
+
+	// the body isn't encoded anymore and its length is unknown
+	request.Header.Del("Content-Encoding")
+	request.ContentLength = -1
+
+	return nil
+}
+
+// newDeflateReader reads deflate bodies, those are meant to be zlib but plenty of clients send raw deflate
+func newDeflateReader(body io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(body)
+
+	header, err := buffered.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	// zlib's header says it uses deflate and is a multiple of 31
+	isZlib := header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0
+	if isZlib {
+		return zlib.NewReader(buffered)
+	}
+
+	return flate.NewReader(buffered), nil
+}
+
+// readCloser reads from the decompressor but closes the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
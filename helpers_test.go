@@ -2,9 +2,15 @@ package hapi
 
 import (
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -112,6 +118,47 @@ func TestUnmarshalBody(t *testing.T) {
 			shouldError:   true,
 			expectedError: "request body is too large: http: request body too large",
 		},
+		{
+			desc:           "gzip body",
+			request:        newEncodedRequest(t, "gzip", `{"text":"hello world"}`),
+			expectedStruct: testStruct{Text: "hello world"},
+		},
+		{
+			desc:           "zlib deflate body",
+			request:        newEncodedRequest(t, "deflate", `{"text":"hello world"}`),
+			expectedStruct: testStruct{Text: "hello world"},
+		},
+		{
+			desc:           "raw deflate body",
+			request:        newEncodedRequest(t, "raw deflate", `{"text":"hello world"}`),
+			expectedStruct: testStruct{Text: "hello world"},
+		},
+		{
+			desc:           "identity body",
+			request:        newEncodedRequest(t, "identity", `{"text":"hello world"}`),
+			expectedStruct: testStruct{Text: "hello world"},
+		},
+		{
+			desc:    "max bytes option applies to decompressed body",
+			request: newEncodedRequest(t, "gzip", `{"text":"`+strings.Repeat("a", 1000)+`"}`),
+			opts: []UnmarshalOption{
+				WithMaxSize(nil, 100),
+			},
+			shouldError:   true,
+			expectedError: "request body is too large: http: request body too large",
+		},
+		{
+			desc:          "body is not gzip",
+			request:       newEncodedRequest(t, "identity", `{"text":"hello world"}`, "gzip"),
+			shouldError:   true,
+			expectedError: "request body is not proper gzip: gzip: invalid header",
+		},
+		{
+			desc:          "unknown encoding",
+			request:       newEncodedRequest(t, "identity", `{"text":"hello world"}`, "br"),
+			shouldError:   true,
+			expectedError: "content encoding br is not supported",
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.desc, func(t *testing.T) {
@@ -128,3 +175,83 @@ func TestUnmarshalBody(t *testing.T) {
 		})
 	}
 }
+
+// newEncodedRequest compresses body with encoding, contentEncoding is the header sent if it isn't the same
+func newEncodedRequest(t *testing.T, encoding, body string, contentEncoding ...string) *http.Request {
+	var buf bytes.Buffer
+	var writer io.WriteCloser
+	var err error
+
+	switch encoding {
+	case "gzip":
+		writer = gzip.NewWriter(&buf)
+	case "deflate":
+		writer = zlib.NewWriter(&buf)
+	case "raw deflate":
+		writer, err = flate.NewWriter(&buf, flate.DefaultCompression)
+		encoding = "deflate"
+	default:
+		writer = nopWriteCloser{Writer: &buf}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = writer.Write([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = writer.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, "/", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(contentEncoding) > 0 {
+		encoding = contentEncoding[0]
+	}
+	request.Header.Set("Content-Encoding", encoding)
+
+	return request
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestUnmarshalBodyUnsupportedEncoding(t *testing.T) {
+	request := newEncodedRequest(t, "identity", `{"text":"hello world"}`, "gzip, br")
+
+	var actualStruct testStruct
+	err := UnmarshalBody(request, &actualStruct)
+
+	recorder := httptest.NewRecorder()
+	respondErr := RespondError(recorder, err)
+	if respondErr != nil {
+		t.Fatal(respondErr)
+	}
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+	assert.Equal(t, "gzip, deflate", recorder.Header().Get("Accept-Encoding"))
+	assert.JSONEq(t, `{"error":"content encoding br is not supported"}`, recorder.Body.String())
+}
+
+func TestUnmarshalBodyDecodedHeaders(t *testing.T) {
+	request := newEncodedRequest(t, "gzip", `{"text":"hello world"}`)
+
+	var actualStruct testStruct
+	err := UnmarshalBody(request, &actualStruct)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", request.Header.Get("Content-Encoding"))
+	assert.Equal(t, int64(-1), request.ContentLength)
+}
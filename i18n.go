@@ -0,0 +1,187 @@
+package hapi
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	goerrors "github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Catalog translates message keys into messages for the client
+type Catalog interface {
+	// Translate returns the message for key in lang with args filled in. It returns
+	// false if there is no message for key in lang.
+	Translate(lang, key string, args map[string]interface{}) (string, bool)
+}
+
+// MessageCatalog is a Catalog of text/template messages, e.g. "could not find user {{.id}}"
+type MessageCatalog struct {
+	mu       sync.RWMutex
+	messages map[string]map[string]*template.Template
+}
+
+// NewMessageCatalog creates an empty MessageCatalog
+func NewMessageCatalog() *MessageCatalog {
+	return &MessageCatalog{
+		messages: make(map[string]map[string]*template.Template),
+	}
+}
+
+// Add adds the message for key in lang. If an arg the message uses is missing, Translate returns
+// false so the error's own message is sent instead.
+func (c *MessageCatalog) Add(lang, key, message string) error {
+	tmpl, err := template.New(key).Option("missingkey=error").Parse(message)
+	if err != nil {
+		return goerrors.Wrapf(err, "failed to parse message %s for %s", key, lang)
+	}
+
+	lang = normalizeLanguage(lang)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.messages[lang] == nil {
+		c.messages[lang] = make(map[string]*template.Template)
+	}
+
+	c.messages[lang][key] = tmpl
+
+	return nil
+}
+
+// Load adds every message in data for lang. data is a YAML or JSON object of keys to messages.
+func (c *MessageCatalog) Load(lang string, data []byte) error {
+	// JSON is YAML so yaml can do both
+	var messages map[string]string
+	err := yaml.Unmarshal(data, &messages)
+	if err != nil {
+		return goerrors.Wrapf(err, "failed to unmarshal messages for %s", lang)
+	}
+
+	for key, message := range messages {
+		err = c.Add(lang, key, message)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadFile loads a .yaml, .yml or .json file of messages. The language comes from the name
+// of the file, e.g. messages/fr-CA.yaml has the messages for fr-CA.
+func (c *MessageCatalog) LoadFile(path string) error {
+	extension := filepath.Ext(path)
+	switch extension {
+	case ".yaml", ".yml", ".json":
+	default:
+		return goerrors.Errorf("unsupported message file %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return goerrors.Wrapf(err, "failed to read message file %s", path)
+	}
+
+	return c.Load(strings.TrimSuffix(filepath.Base(path), extension), data)
+}
+
+// Translate returns the message for key in lang with args filled in
+func (c *MessageCatalog) Translate(lang, key string, args map[string]interface{}) (string, bool) {
+	c.mu.RLock()
+	tmpl, ok := c.messages[normalizeLanguage(lang)][key]
+	c.mu.RUnlock()
+
+	if !ok {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, args)
+	if err != nil {
+		return "", false
+	}
+
+	return buf.String(), true
+}
+
+// translateMessage finds the translation of the error's message key for the languages in the
+// Accept-Language header. It returns the language that was used, or false if there wasn't one.
+func translateMessage(r *http.Request, key string, args map[string]interface{}) (string, string, bool) {
+	if Config.Catalog == nil || r == nil || key == "" {
+		return "", "", false
+	}
+
+	for _, lang := range acceptedLanguages(r.Header.Get("Accept-Language")) {
+		message, ok := Config.Catalog.Translate(lang, key, args)
+		if ok {
+			return message, lang, true
+		}
+	}
+
+	return "", "", false
+}
+
+type acceptedLanguage struct {
+	lang    string
+	quality float64
+}
+
+// acceptedLanguages parses an Accept-Language header into languages from most to least preferred.
+// Each region specific language (en-GB) is followed by its base language (en) as a fallback.
+func acceptedLanguages(header string) []string {
+	var accepted []acceptedLanguage
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+
+		lang := strings.TrimSpace(fields[0])
+		if lang == "" || lang == "*" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err == nil {
+					quality = q
+				}
+			}
+		}
+
+		if quality <= 0 {
+			continue
+		}
+
+		accepted = append(accepted, acceptedLanguage{lang: lang, quality: quality})
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool {
+		return accepted[i].quality > accepted[j].quality
+	})
+
+	var langs []string
+	for _, a := range accepted {
+		langs = append(langs, a.lang)
+
+		base := strings.SplitN(a.lang, "-", 2)[0]
+		if base != a.lang {
+			langs = append(langs, base)
+		}
+	}
+
+	return langs
+}
+
+func normalizeLanguage(lang string) string {
+	return strings.ToLower(lang)
+}
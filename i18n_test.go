@@ -0,0 +1,235 @@
+package hapi
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+func newTestCatalog(t *testing.T) *MessageCatalog {
+	catalog := NewMessageCatalog()
+
+	err := catalog.Load("en", []byte(`
+user.notFound: "could not find user {{.id}}"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = catalog.Load("fr", []byte(`{"user.notFound": "utilisateur {{.id}} introuvable"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = catalog.Add("fr-CA", "user.notFound", "on a pas trouvé l'utilisateur {{.id}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return catalog
+}
+
+func TestRespondErrorTranslates(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	Config.Catalog = newTestCatalog(t)
+
+	err := errors.NotFound.New("could not find user").WithMessageKey("user.notFound", map[string]interface{}{"id": "42"})
+
+	testCases := []struct {
+		desc                    string
+		acceptLanguage          string
+		err                     error
+		withRequest             bool
+		expectedBody            string
+		expectedContentLanguage string
+	}{
+		{
+			desc:                    "exact language",
+			acceptLanguage:          "fr-CA",
+			err:                     err,
+			withRequest:             true,
+			expectedBody:            `{"error":"on a pas trouvé l'utilisateur 42"}`,
+			expectedContentLanguage: "fr-CA",
+		},
+		{
+			desc:                    "falls back to base language",
+			acceptLanguage:          "fr-FR",
+			err:                     err,
+			withRequest:             true,
+			expectedBody:            `{"error":"utilisateur 42 introuvable"}`,
+			expectedContentLanguage: "fr",
+		},
+		{
+			desc:                    "uses quality to pick language",
+			acceptLanguage:          "de;q=0.9, en;q=0.8, fr;q=0.1",
+			err:                     err,
+			withRequest:             true,
+			expectedBody:            `{"error":"could not find user 42"}`,
+			expectedContentLanguage: "en",
+		},
+		{
+			desc:           "no translation falls back to message",
+			acceptLanguage: "de",
+			err:            err,
+			withRequest:    true,
+			expectedBody:   `{"error":"could not find user"}`,
+		},
+		{
+			desc:           "missing args fall back to message",
+			acceptLanguage: "fr",
+			err:            errors.NotFound.New("could not find user").WithMessageKey("user.notFound", nil),
+			withRequest:    true,
+			expectedBody:   `{"error":"could not find user"}`,
+		},
+		{
+			desc:           "no request falls back to message",
+			acceptLanguage: "fr",
+			err:            err,
+			expectedBody:   `{"error":"could not find user"}`,
+		},
+		{
+			desc:           "no message key",
+			acceptLanguage: "fr",
+			err:            errors.NotFound.New("could not find user"),
+			withRequest:    true,
+			expectedBody:   `{"error":"could not find user"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+
+			var opts []ResponseOption
+			if tc.withRequest {
+				opts = append(opts, WithRequest(req))
+			}
+
+			recorder := httptest.NewRecorder()
+			err = RespondError(recorder, tc.err, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, http.StatusNotFound, recorder.Code)
+			assert.JSONEq(t, tc.expectedBody, recorder.Body.String())
+			assert.Equal(t, tc.expectedContentLanguage, recorder.Header().Get("Content-Language"))
+		})
+	}
+}
+
+func TestRespondErrorTranslatesSanitizedServerErrors(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	Config.Catalog = newTestCatalog(t)
+	Config.SanitizeServerErrors = true
+
+	testCases := []struct {
+		desc                    string
+		acceptLanguage          string
+		expectedBody            string
+		expectedContentLanguage string
+	}{
+		{
+			desc:                    "translation is kept",
+			acceptLanguage:          "fr",
+			expectedBody:            `{"error":"utilisateur 42 introuvable","retryable":true}`,
+			expectedContentLanguage: "fr",
+		},
+		{
+			desc:           "no translation is sanitized",
+			acceptLanguage: "de",
+			expectedBody:   fmt.Sprintf(`{"error":"%s","retryable":true}`, Config.DefaultErrorMessage),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Accept-Language", tc.acceptLanguage)
+
+			hapiErr := errors.ServiceUnavailable.New("redis at 10.0.0.1 is down").WithMessageKey("user.notFound", map[string]interface{}{"id": "42"})
+
+			recorder := httptest.NewRecorder()
+			err = RespondError(recorder, hapiErr, WithRequest(req))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+			assert.JSONEq(t, tc.expectedBody, recorder.Body.String())
+			assert.Equal(t, tc.expectedContentLanguage, recorder.Header().Get("Content-Language"))
+		})
+	}
+}
+
+func TestMessageCatalogLoadFile(t *testing.T) {
+	dir, err := os.MkdirTemp("", "hapi-messages")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"en.yaml": `user.notFound: "could not find user {{.id}}"`,
+		"es.json": `{"user.notFound": "no se encontró el usuario {{.id}}"}`,
+		"de.txt":  `user.notFound: nope`,
+	}
+	for name, content := range files {
+		err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	catalog := NewMessageCatalog()
+	assert.NoError(t, catalog.LoadFile(filepath.Join(dir, "en.yaml")))
+	assert.NoError(t, catalog.LoadFile(filepath.Join(dir, "es.json")))
+	assert.Error(t, catalog.LoadFile(filepath.Join(dir, "de.txt")))
+	assert.Error(t, catalog.LoadFile(filepath.Join(dir, "missing.yaml")))
+
+	message, ok := catalog.Translate("es", "user.notFound", map[string]interface{}{"id": "42"})
+	assert.True(t, ok)
+	assert.Equal(t, "no se encontró el usuario 42", message)
+
+	_, ok = catalog.Translate("de", "user.notFound", nil)
+	assert.False(t, ok)
+}
+
+func TestAcceptedLanguages(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		header   string
+		expected []string
+	}{
+		{
+			desc:     "empty",
+			header:   "",
+			expected: nil,
+		},
+		{
+			desc:     "ordered by quality with base languages",
+			header:   "en-GB;q=0.5, fr-CA, *;q=0.1, de;q=0",
+			expected: []string{"fr-CA", "fr", "en-GB", "en"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, acceptedLanguages(tc.header))
+		})
+	}
+}
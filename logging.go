@@ -0,0 +1,115 @@
+package hapi
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+// ErrorEntry is everything we know about an error response. It is given to every
+// ErrorHook so that the raw error doesn't get lost when it isn't returned to the client.
+type ErrorEntry struct {
+	StatusCode int
+
+	// ErrorType is only set when the error is a HapiError
+	ErrorType errors.ErrorType
+
+	// Message is the message of the error, before it is sanitized for the client
+	Message string
+
+	// Err is the raw error, including the whole chain of wrapped errors
+	Err error
+
+	// Method, Path and RequestID are only set when the request was given with WithRequest
+	Method    string
+	Path      string
+	RequestID string
+}
+
+// ErrorHook is called every time RespondError writes an error response
+type ErrorHook func(ctx context.Context, entry ErrorEntry)
+
+func newErrorEntry(w http.ResponseWriter, r *http.Request, err error, statusCode int, message string) ErrorEntry {
+	entry := ErrorEntry{
+		StatusCode: statusCode,
+		Message:    message,
+		Err:        err,
+		RequestID:  responseRequestID(w, r),
+		ErrorType:  errorTypeOf(err),
+	}
+
+	if r != nil {
+		entry.Method = r.Method
+		entry.Path = r.URL.Path
+	}
+
+	return entry
+}
+
+func runErrorHooks(r *http.Request, entry ErrorEntry) {
+	if len(Config.ErrorHooks) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+
+	for _, hook := range Config.ErrorHooks {
+		hook(ctx, entry)
+	}
+}
+
+// DefaultLogLevel is the level SlogHook logs at when there is no level for the
+// ErrorType. 5xx are errors, 4xx are warnings and everything else is info. Clients
+// closing the request are info since there is nothing for us to fix.
+func DefaultLogLevel(statusCode int) slog.Level {
+	switch {
+	case statusCode == errors.StatusClientClosedRequest:
+		return slog.LevelInfo
+	case statusCode >= 500:
+		return slog.LevelError
+	case statusCode >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// SlogHook creates an ErrorHook that logs every error response to logger. levels can be used to
+// change the level for a given ErrorType, anything not in levels will use DefaultLogLevel.
+func SlogHook(logger *slog.Logger, levels map[errors.ErrorType]slog.Level) ErrorHook {
+	return func(ctx context.Context, entry ErrorEntry) {
+		level, ok := levels[entry.ErrorType]
+		if !ok {
+			level = DefaultLogLevel(entry.StatusCode)
+		}
+
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+
+		attrs := []slog.Attr{
+			slog.Int("status", entry.StatusCode),
+			slog.String("errorType", entry.ErrorType.String()),
+			slog.String("message", entry.Message),
+		}
+
+		if entry.Err != nil {
+			attrs = append(attrs, slog.String("error", entry.Err.Error()))
+		}
+
+		if entry.Method != "" {
+			attrs = append(attrs, slog.String("method", entry.Method), slog.String("path", entry.Path))
+		}
+
+		if entry.RequestID != "" {
+			attrs = append(attrs, slog.String("requestId", entry.RequestID))
+		}
+
+		logger.LogAttrs(ctx, level, "error response", attrs...)
+	}
+}
@@ -0,0 +1,145 @@
+package hapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+func TestRespondErrorHooks(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	var entries []ErrorEntry
+	Config.ErrorHooks = []ErrorHook{
+		func(ctx context.Context, entry ErrorEntry) {
+			entries = append(entries, entry)
+		},
+	}
+
+	testCases := []struct {
+		desc          string
+		err           error
+		withRequest   bool
+		expectedEntry ErrorEntry
+	}{
+		{
+			desc:        "hapi error with request",
+			err:         errors.NotFound.Wrap(goerrors.New("sql: no rows in result set"), "could not find user"),
+			withRequest: true,
+			expectedEntry: ErrorEntry{
+				StatusCode: http.StatusNotFound,
+				ErrorType:  errors.NotFound,
+				Message:    "could not find user",
+				Method:     http.MethodGet,
+				Path:       "/users/42",
+				RequestID:  "abc-123",
+			},
+		},
+		{
+			desc: "standard go error without request",
+			err:  goerrors.New("some go error"),
+			expectedEntry: ErrorEntry{
+				StatusCode: Config.DefaultStatusCode,
+				Message:    Config.DefaultErrorMessage,
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			entries = nil
+
+			req, err := http.NewRequest(http.MethodGet, "/users/42", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = req.WithContext(ContextWithRequestID(req.Context(), "abc-123"))
+
+			var opts []ResponseOption
+			if tc.withRequest {
+				opts = append(opts, WithRequest(req))
+			}
+
+			err = RespondError(httptest.NewRecorder(), tc.err, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if assert.Len(t, entries, 1) {
+				actual := entries[0]
+				assert.Equal(t, tc.err, actual.Err)
+
+				actual.Err = nil
+				assert.Equal(t, tc.expectedEntry, actual)
+			}
+		})
+	}
+}
+
+func TestSlogHook(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		levels        map[errors.ErrorType]slog.Level
+		entry         ErrorEntry
+		expectedLevel string
+	}{
+		{
+			desc: "5xx logs at error",
+			entry: ErrorEntry{
+				StatusCode: http.StatusInternalServerError,
+				ErrorType:  errors.InternalServerError,
+				Message:    "something broke",
+				Err:        goerrors.Wrap(goerrors.New("connection refused"), "failed to query db"),
+			},
+			expectedLevel: "ERROR",
+		},
+		{
+			desc: "4xx logs at warn",
+			entry: ErrorEntry{
+				StatusCode: http.StatusBadRequest,
+				ErrorType:  errors.BadRequest,
+			},
+			expectedLevel: "WARN",
+		},
+		{
+			desc: "level for error type overrides default",
+			levels: map[errors.ErrorType]slog.Level{
+				errors.NotFound: slog.LevelInfo,
+			},
+			entry: ErrorEntry{
+				StatusCode: http.StatusNotFound,
+				ErrorType:  errors.NotFound,
+			},
+			expectedLevel: "INFO",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			SlogHook(logger, tc.levels)(context.Background(), tc.entry)
+
+			var record map[string]interface{}
+			err := json.Unmarshal(buf.Bytes(), &record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedLevel, record["level"])
+			assert.Equal(t, float64(tc.entry.StatusCode), record["status"])
+			assert.Equal(t, tc.entry.ErrorType.String(), record["errorType"])
+			if tc.entry.Err != nil {
+				assert.Equal(t, tc.entry.Err.Error(), record["error"])
+			}
+		})
+	}
+}
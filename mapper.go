@@ -0,0 +1,112 @@
+package hapi
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"os"
+
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+// ErrorMapper turns an error that isn't a HapiError into one so RespondError can respond with the
+// right status code. It returns false if it doesn't know the error.
+type ErrorMapper func(err error) (errors.HapiError, bool)
+
+// MapErrorIs creates an ErrorMapper for any error in err's chain that matches target.
+// If message is empty, the status text for the ErrorType is sent to the client.
+func MapErrorIs(target error, errorType errors.ErrorType, message string) ErrorMapper {
+	return func(err error) (errors.HapiError, bool) {
+		if !errors.Is(err, target) {
+			return errors.HapiError{}, false
+		}
+
+		return errorType.Cast(err, message), true
+	}
+}
+
+// DefaultErrorMappers are the ErrorMappers Config starts with
+var DefaultErrorMappers = []ErrorMapper{
+	MapErrorIs(sql.ErrNoRows, errors.NotFound, ""),
+	MapErrorIs(context.DeadlineExceeded, errors.GatewayTimeout, ""),
+	MapErrorIs(context.Canceled, errors.ClientClosedRequest, "client closed request"),
+	MapErrorIs(os.ErrPermission, errors.Forbidden, ""),
+	mapMaxBytesError,
+}
+
+func mapMaxBytesError(err error) (errors.HapiError, bool) {
+	var maxBytesError *http.MaxBytesError
+	if !errors.As(err, &maxBytesError) {
+		return errors.HapiError{}, false
+	}
+
+	return errors.TooLarge.Cast(err, "request body is too large"), true
+}
+
+// RegisterErrorMapper adds mapper to Config.ErrorMappers. Mappers that are registered are tried
+// before the ones already in Config.ErrorMappers so they can override the defaults.
+func RegisterErrorMapper(mapper ErrorMapper) {
+	Config.ErrorMappers = append([]ErrorMapper{mapper}, Config.ErrorMappers...)
+}
+
+// mapError runs err through Config.ErrorMappers if it isn't already a hapiError (or is one with
+// NoType, in which case its message is kept). If none of the mappers know the error, it is returned
+// as is. Joined errors become a MultiError with each of their errors mapped.
+func mapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch e := err.(type) {
+	case errors.HapiError:
+		if e.ErrorType != errors.NoType {
+			return err
+		}
+	case *errors.MultiError:
+		precedence := e.Precedence
+		if precedence == nil {
+			precedence = Config.MultiErrorPrecedence
+		}
+
+		return &errors.MultiError{
+			Errs:       mapErrors(e.Errs),
+			Message:    e.Message,
+			Precedence: precedence,
+		}
+	case hapiError:
+		return err
+	case interface{ Unwrap() []error }:
+		return &errors.MultiError{
+			Errs:       mapErrors(e.Unwrap()),
+			Precedence: Config.MultiErrorPrecedence,
+		}
+	}
+
+	for _, mapper := range Config.ErrorMappers {
+		hapiErr, ok := mapper(err)
+		if !ok {
+			continue
+		}
+
+		// the mapper only knows the cause, the message the developer gave is better
+		original, ok := err.(errors.HapiError)
+		if ok && original.Message != "" {
+			hapiErr.Message = original.Message
+		}
+
+		return hapiErr
+	}
+
+	return err
+}
+
+func mapErrors(errs []error) []error {
+	mapped := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			mapped = append(mapped, mapError(err))
+		}
+	}
+
+	return mapped
+}
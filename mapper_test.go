@@ -0,0 +1,124 @@
+package hapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+var errSomethingCustom = goerrors.New("something custom")
+
+func TestRespondErrorMapsErrors(t *testing.T) {
+	maxBytesReq := &http.Request{
+		Body: http.MaxBytesReader(nil, io.NopCloser(strings.NewReader(`{"text":"hello world"}`)), 1),
+	}
+	var v interface{}
+	maxBytesErr := json.NewDecoder(maxBytesReq.Body).Decode(&v)
+
+	testCases := []struct {
+		desc               string
+		err                error
+		customMapper       ErrorMapper
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			desc:               "sql no rows",
+			err:                goerrors.Wrap(sql.ErrNoRows, "failed to get user"),
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `{"error":"Not Found"}`,
+		},
+		{
+			desc:               "no type hapi error wrapping sql no rows keeps its message",
+			err:                errors.Wrap(sql.ErrNoRows, "user not found"),
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody:       `{"error":"user not found"}`,
+		},
+		{
+			desc:               "no type hapi error without a message gets the mapper's",
+			err:                errors.NoType.Cast(fmt.Errorf("failed to query: %w", context.Canceled), ""),
+			expectedStatusCode: errors.StatusClientClosedRequest,
+			expectedBody:       `{"error":"client closed request"}`,
+		},
+		{
+			desc:               "hapi error wrapping sql no rows keeps its type",
+			err:                errors.BadRequest.Wrap(sql.ErrNoRows, "no user with that id"),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"error":"no user with that id"}`,
+		},
+		{
+			desc:               "deadline exceeded",
+			err:                context.DeadlineExceeded,
+			expectedStatusCode: http.StatusGatewayTimeout,
+			expectedBody:       `{"error":"Gateway Timeout","retryable":true}`,
+		},
+		{
+			desc:               "canceled",
+			err:                fmt.Errorf("failed to query: %w", context.Canceled),
+			expectedStatusCode: errors.StatusClientClosedRequest,
+			expectedBody:       `{"error":"client closed request"}`,
+		},
+		{
+			desc:               "permission",
+			err:                &os.PathError{Op: "open", Path: "/secret", Err: os.ErrPermission},
+			expectedStatusCode: http.StatusForbidden,
+			expectedBody:       `{"error":"Forbidden"}`,
+		},
+		{
+			desc:               "max bytes",
+			err:                maxBytesErr,
+			expectedStatusCode: http.StatusRequestEntityTooLarge,
+			expectedBody:       `{"error":"request body is too large"}`,
+		},
+		{
+			desc:               "custom mapper",
+			err:                goerrors.Wrap(errSomethingCustom, "oops"),
+			customMapper:       MapErrorIs(errSomethingCustom, errors.ImATeapot, "i'm a little teapot"),
+			expectedStatusCode: http.StatusTeapot,
+			expectedBody:       `{"error":"i'm a little teapot"}`,
+		},
+		{
+			desc:               "custom mapper overrides default",
+			err:                sql.ErrNoRows,
+			customMapper:       MapErrorIs(sql.ErrNoRows, errors.BadRequest, "no such thing"),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"error":"no such thing"}`,
+		},
+		{
+			desc:               "unknown error",
+			err:                goerrors.New("some go error"),
+			expectedStatusCode: Config.DefaultStatusCode,
+			expectedBody:       fmt.Sprintf(`{"error":"%s"}`, Config.DefaultErrorMessage),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			originalConfig := Config
+			defer func() { Config = originalConfig }()
+
+			if tc.customMapper != nil {
+				RegisterErrorMapper(tc.customMapper)
+			}
+
+			recorder := httptest.NewRecorder()
+			err := RespondError(recorder, tc.err)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			assert.JSONEq(t, tc.expectedBody, recorder.Body.String())
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package hapi
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+// ResponseMetric is a single response that was observed by Instrument
+type ResponseMetric struct {
+	Method     string
+	Route      string
+	StatusCode int
+
+	// ErrorType is only set when the response was written by RespondError with a HapiError
+	ErrorType errors.ErrorType
+
+	Duration time.Duration
+	Size     int64
+}
+
+// Metrics records response metrics. Implement it with Prometheus or OpenTelemetry counters and histograms,
+// or use MemoryMetrics in tests.
+type Metrics interface {
+	ObserveResponse(metric ResponseMetric)
+}
+
+// Instrument creates middleware that observes every response in metrics. route is what the
+// response is labeled with, if it is empty then the pattern http.ServeMux matched is used. That
+// only works when Instrument wraps the handler registered with the mux, not the mux itself.
+func Instrument(metrics Metrics, route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			iw := &instrumentedWriter{ResponseWriter: w}
+
+			next.ServeHTTP(iw, r)
+
+			metric := ResponseMetric{
+				Method:     r.Method,
+				Route:      route,
+				StatusCode: iw.statusCode,
+				ErrorType:  iw.errorType,
+				Duration:   time.Since(start),
+				Size:       iw.size,
+			}
+
+			if metric.Route == "" {
+				metric.Route = r.Pattern
+			}
+
+			// net/http sends a 200 if the handler never called WriteHeader
+			if metric.StatusCode == 0 {
+				metric.StatusCode = http.StatusOK
+			}
+
+			metrics.ObserveResponse(metric)
+		})
+	}
+}
+
+type instrumentedWriter struct {
+	http.ResponseWriter
+
+	statusCode int
+	size       int64
+	errorType  errors.ErrorType
+}
+
+func (iw *instrumentedWriter) WriteHeader(statusCode int) {
+	if iw.statusCode == 0 {
+		iw.statusCode = statusCode
+	}
+
+	iw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (iw *instrumentedWriter) Write(bytes []byte) (int, error) {
+	if iw.statusCode == 0 {
+		iw.statusCode = http.StatusOK
+	}
+
+	n, err := iw.ResponseWriter.Write(bytes)
+	iw.size += int64(n)
+
+	return n, err
+}
+
+// Flush lets streaming handlers flush through the instrumented writer
+func (iw *instrumentedWriter) Flush() {
+	flusher, ok := iw.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController get to the original http.ResponseWriter
+func (iw *instrumentedWriter) Unwrap() http.ResponseWriter {
+	return iw.ResponseWriter
+}
+
+func (iw *instrumentedWriter) recordErrorType(errorType errors.ErrorType) {
+	iw.errorType = errorType
+}
+
+type errorTypeRecorder interface {
+	recordErrorType(errorType errors.ErrorType)
+}
+
+// recordErrorType lets Instrument know the ErrorType of the response, even if w has been
+// wrapped by other middleware
+func recordErrorType(w http.ResponseWriter, errorType errors.ErrorType) {
+	for w != nil {
+		recorder, ok := w.(errorTypeRecorder)
+		if ok {
+			recorder.recordErrorType(errorType)
+			return
+		}
+
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+
+		w = unwrapper.Unwrap()
+	}
+}
+
+// MemoryMetrics is Metrics that keeps every response in memory, it is useful for tests
+type MemoryMetrics struct {
+	mu      sync.Mutex
+	metrics []ResponseMetric
+}
+
+// ObserveResponse adds the metric to memory
+func (m *MemoryMetrics) ObserveResponse(metric ResponseMetric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.metrics = append(m.metrics, metric)
+}
+
+// Metrics gets all the metrics that have been observed
+func (m *MemoryMetrics) Metrics() []ResponseMetric {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics := make([]ResponseMetric, len(m.metrics))
+	copy(metrics, m.metrics)
+
+	return metrics
+}
+
+// Count gets how many responses were observed for the route with the status code
+func (m *MemoryMetrics) Count(route string, statusCode int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var count int
+	for _, metric := range m.metrics {
+		if metric.Route == route && metric.StatusCode == statusCode {
+			count++
+		}
+	}
+
+	return count
+}
@@ -0,0 +1,92 @@
+package hapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+func TestInstrument(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		route              string
+		handler            http.HandlerFunc
+		expectedRoute      string
+		expectedStatusCode int
+		expectedErrorType  errors.ErrorType
+		expectedSize       int64
+	}{
+		{
+			desc:  "ok response",
+			route: "/users/{id}",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_ = RespondOK(w, "hello world")
+			},
+			expectedRoute:      "/users/{id}",
+			expectedStatusCode: http.StatusOK,
+			expectedSize:       int64(len(`"hello world"`)),
+		},
+		{
+			desc:  "hapi error response",
+			route: "/users/{id}",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_ = RespondError(w, errors.NotFound.New("nope"))
+			},
+			expectedRoute:      "/users/{id}",
+			expectedStatusCode: http.StatusNotFound,
+			expectedErrorType:  errors.NotFound,
+			expectedSize:       int64(len(`{"error":"nope"}`)),
+		},
+		{
+			desc: "handler never writes",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			metrics := &MemoryMetrics{}
+			handler := Instrument(metrics, tc.route)(tc.handler)
+
+			req, err := http.NewRequest(http.MethodGet, "/users/42", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			observed := metrics.Metrics()
+			if assert.Len(t, observed, 1) {
+				assert.Equal(t, http.MethodGet, observed[0].Method)
+				assert.Equal(t, tc.expectedRoute, observed[0].Route)
+				assert.Equal(t, tc.expectedStatusCode, observed[0].StatusCode)
+				assert.Equal(t, tc.expectedErrorType, observed[0].ErrorType)
+				assert.Equal(t, tc.expectedSize, observed[0].Size)
+			}
+			assert.Equal(t, 1, metrics.Count(tc.expectedRoute, tc.expectedStatusCode))
+		})
+	}
+}
+
+func TestInstrumentServeMuxPattern(t *testing.T) {
+	metrics := &MemoryMetrics{}
+	handler := Instrument(metrics, "")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = RespondOK(w, "hello world")
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "/users/42", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// this is what http.ServeMux sets when it matches a pattern
+	req.Pattern = "GET /users/{id}"
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, 1, metrics.Count("GET /users/{id}", http.StatusOK))
+}
@@ -0,0 +1,70 @@
+package hapi
+
+import "net/http"
+
+// ItemResult is the result of a single item in a batch, it is either a Payload or an Err
+type ItemResult struct {
+	// ID is optional and helps the client match results to the items it sent
+	ID string
+
+	// StatusCode is the status of a successful item, it defaults to 200
+	StatusCode int
+	Payload    interface{}
+
+	Err error
+}
+
+// MultiStatusResponse is the body of a 207 Multi-Status response
+type MultiStatusResponse struct {
+	Results []MultiStatusItem `json:"results"`
+}
+
+// MultiStatusItem is the result of a single item in a MultiStatusResponse. Successful items
+// have a Body and failed items have an Error.
+type MultiStatusItem struct {
+	ID     string         `json:"id,omitempty"`
+	Status int            `json:"status"`
+	Body   interface{}    `json:"body,omitempty"`
+	Error  *ErrorResponse `json:"error,omitempty"`
+}
+
+// RespondMultiStatus will respond with a 207 status code and the status of every item in results.
+// Failed items get the same error body that RespondError would send for their error, and their
+// errors are given to the ErrorHooks and ErrorReporter just like RespondError.
+func RespondMultiStatus(w http.ResponseWriter, results []ItemResult, opts ...ResponseOption) error {
+	o := newResponseOptions(opts)
+
+	response := MultiStatusResponse{
+		Results: make([]MultiStatusItem, 0, len(results)),
+	}
+
+	for _, result := range results {
+		item := MultiStatusItem{
+			ID: result.ID,
+		}
+
+		if result.Err != nil {
+			err := mapError(result.Err)
+
+			statusCode, message, errorResponse := newErrorResponse(w, o.request, err, Config.DefaultStatusCode)
+			observeError(w, o, err, statusCode, message)
+
+			// the request id is already in the header, no need to repeat it for every item
+			errorResponse.RequestID = ""
+
+			item.Status = statusCode
+			item.Error = &errorResponse
+		} else {
+			item.Status = result.StatusCode
+			if item.Status == 0 {
+				item.Status = http.StatusOK
+			}
+
+			item.Body = result.Payload
+		}
+
+		response.Results = append(response.Results, item)
+	}
+
+	return Respond(w, http.StatusMultiStatus, response, opts...)
+}
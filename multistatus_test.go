@@ -0,0 +1,78 @@
+package hapi
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+func TestRespondMultiStatus(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	var entries []ErrorEntry
+	Config.ErrorHooks = []ErrorHook{
+		func(ctx context.Context, entry ErrorEntry) {
+			entries = append(entries, entry)
+		},
+	}
+
+	results := []ItemResult{
+		{
+			ID:         "1",
+			StatusCode: http.StatusCreated,
+			Payload:    map[string]string{"name": "stephen"},
+		},
+		{
+			ID:      "2",
+			Payload: map[string]string{"name": "someone"},
+		},
+		{
+			ID:  "3",
+			Err: errors.BadRequest.New("name is required"),
+		},
+		{
+			ID:  "4",
+			Err: goerrors.Wrap(sql.ErrNoRows, "failed to get team"),
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	err := RespondMultiStatus(recorder, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, http.StatusMultiStatus, recorder.Code)
+	assert.JSONEq(t, `
+	{
+		"results": [
+			{"id": "1", "status": 201, "body": {"name": "stephen"}},
+			{"id": "2", "status": 200, "body": {"name": "someone"}},
+			{"id": "3", "status": 400, "error": {"error": "name is required"}},
+			{"id": "4", "status": 404, "error": {"error": "Not Found"}}
+		]
+	}`, recorder.Body.String())
+
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, errors.BadRequest, entries[0].ErrorType)
+		assert.Equal(t, errors.NotFound, entries[1].ErrorType)
+	}
+}
+
+func TestRespondMultiStatusEmpty(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := RespondMultiStatus(recorder, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, http.StatusMultiStatus, recorder.Code)
+	assert.JSONEq(t, `{"results":[]}`, recorder.Body.String())
+}
@@ -0,0 +1,162 @@
+package hapi
+
+import (
+	"context"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+// ResponseOption is an option given to the responders
+type ResponseOption func(o *responseOptions)
+
+type responseOptions struct {
+	request *http.Request
+
+	// panicStack is set by Recover so the report has the stack of the panic
+	panicStack string
+
+	// etag, bodyETag and lastModified are the validators for conditional requests, see etag.go
+	etag         string
+	bodyETag     bool
+	weakETag     bool
+	lastModified time.Time
+
+	// cacheControl is nil unless WithCacheControl was used, see cache.go
+	cacheControl []CacheDirective
+	vary         []string
+
+	headers     http.Header
+	cookies     []*http.Cookie
+	contentType string
+}
+
+func newResponseOptions(opts []ResponseOption) responseOptions {
+	var o responseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithRequest gives the responder the request that is being responded to so that
+// things like the request's context, method and path can be used
+func WithRequest(r *http.Request) ResponseOption {
+	return func(o *responseOptions) {
+		o.request = r
+	}
+}
+
+// WithETag sets the ETag header of a successful response, etag can be given with or without
+// quotes, e.g. v42, "v42" or W/"v42". If the request is given with WithRequest and its
+// If-None-Match has the ETag, a 304 Not Modified is sent instead of the payload.
+func WithETag(etag string) ResponseOption {
+	return func(o *responseOptions) {
+		o.etag = formatETag(etag)
+	}
+}
+
+// WithBodyETag is like WithETag but the ETag is a hash of the encoded payload. It is a weak
+// ETag if weak is true.
+func WithBodyETag(weak bool) ResponseOption {
+	return func(o *responseOptions) {
+		o.bodyETag = true
+		o.weakETag = weak
+	}
+}
+
+// WithLastModified sets the Last-Modified header of a successful response. If the request is
+// given with WithRequest and it hasn't changed since If-Modified-Since, a 304 Not Modified is
+// sent instead of the payload. If-None-Match wins if the request has both.
+func WithLastModified(modified time.Time) ResponseOption {
+	return func(o *responseOptions) {
+		o.lastModified = modified
+	}
+}
+
+// WithCacheControl sets the Cache-Control header to directives, e.g.
+// WithCacheControl(CachePublic, CacheMaxAge(time.Minute)). Error responses are sent with
+// no-store unless this is given to RespondError.
+func WithCacheControl(directives ...CacheDirective) ResponseOption {
+	return func(o *responseOptions) {
+		o.cacheControl = append([]CacheDirective{}, directives...)
+	}
+}
+
+// WithVary adds headers to the Vary header, headers that are already in it aren't added again.
+func WithVary(headers ...string) ResponseOption {
+	return func(o *responseOptions) {
+		o.vary = append(o.vary, headers...)
+	}
+}
+
+// WithHeader adds value to the response header key, giving the same key more than once sends
+// every value. It replaces any values that were already set on the http.ResponseWriter.
+func WithHeader(key, value string) ResponseOption {
+	return func(o *responseOptions) {
+		if o.headers == nil {
+			o.headers = make(http.Header)
+		}
+
+		o.headers.Add(key, value)
+	}
+}
+
+// WithCookie adds a Set-Cookie header for cookie to the response.
+func WithCookie(cookie *http.Cookie) ResponseOption {
+	return func(o *responseOptions) {
+		o.cookies = append(o.cookies, cookie)
+	}
+}
+
+// WithContentType sets the Content-Type of the response, it is application/json by default.
+// The payload is still marshalled as json so use it for types like application/problem+json.
+func WithContentType(contentType string) ResponseOption {
+	return func(o *responseOptions) {
+		o.contentType = contentType
+	}
+}
+
+// WithLocation sets the Location header, e.g. to the url of the resource a 201 Created made.
+func WithLocation(url string) ResponseOption {
+	return WithHeader("Location", url)
+}
+
+func withPanicStack(stack string) ResponseOption {
+	return func(o *responseOptions) {
+		o.panicStack = stack
+	}
+}
+
+// setHeaders sets the headers and cookies from WithHeader and WithCookie
+func (o responseOptions) setHeaders(w http.ResponseWriter) {
+	for key, values := range o.headers {
+		w.Header()[key] = append([]string(nil), values...)
+	}
+
+	for _, cookie := range o.cookies {
+		http.SetCookie(w, cookie)
+	}
+}
+
+// clientClosed returns the context error if the request was given and the client has gone away
+func (o responseOptions) clientClosed() error {
+	if o.request == nil {
+		return nil
+	}
+
+	err := o.request.Context().Err()
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
+
+// isClientClosedError checks if err is from writing to a client that closed the connection
+func isClientClosedError(err error) bool {
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
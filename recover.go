@@ -0,0 +1,85 @@
+package hapi
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+// Recover is middleware that recovers from panics in next, reports them to Config.ErrorReporter
+// and responds with an InternalServerError. If next had already started the response, it is
+// aborted instead since the error can't be sent anymore.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &recoverWriter{ResponseWriter: w}
+
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+
+			// http.ErrAbortHandler is how handlers purposely abort, so let net/http deal with it
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			err := errors.HapiError{
+				ErrorType: errors.InternalServerError,
+				Err:       goerrors.Errorf("panic: %v", recovered),
+				Message:   Config.DefaultErrorMessage,
+			}
+
+			stack := string(debug.Stack())
+
+			// the error would end up on the end of whatever was already sent, so the panic is
+			// still reported but the response is aborted
+			if rw.written {
+				observeError(rw, responseOptions{request: r, panicStack: stack}, err, http.StatusInternalServerError, err.Message)
+				panic(http.ErrAbortHandler)
+			}
+
+			_ = RespondError(rw, err, WithRequest(r), withPanicStack(stack))
+		}()
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// recoverWriter keeps track of whether the response has been started
+type recoverWriter struct {
+	http.ResponseWriter
+
+	written bool
+}
+
+func (rw *recoverWriter) WriteHeader(statusCode int) {
+	// informational responses don't start the response
+	if statusCode >= 200 {
+		rw.written = true
+	}
+
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rw *recoverWriter) Write(bytes []byte) (int, error) {
+	rw.written = true
+
+	return rw.ResponseWriter.Write(bytes)
+}
+
+func (rw *recoverWriter) Flush() {
+	rw.written = true
+
+	flusher, ok := rw.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController get to the original http.ResponseWriter
+func (rw *recoverWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,187 @@
+package hapi
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+// ErrorReport is what gets sent to an ErrorReporter
+type ErrorReport struct {
+	Err        errors.HapiError
+	StatusCode int
+
+	// StackTrace is the stack of where the error was created, or of the panic if Panic is true
+	StackTrace string
+	Panic      bool
+
+	// Method, Path and RequestID are only set when the request was given with WithRequest
+	Method    string
+	Path      string
+	RequestID string
+
+	// User is only set when the request context has a user from ContextWithReportUser
+	User ReportUser
+}
+
+// ReportUser is the user that was making the request when an error was reported
+type ReportUser struct {
+	ID       string
+	Username string
+	Email    string
+}
+
+// ErrorReporter sends errors to an error tracker. RespondError and Recover will report every error
+// with a status code of at least Config.ReportMinStatusCode.
+type ErrorReporter interface {
+	Report(ctx context.Context, report ErrorReport)
+}
+
+type reportUserKey struct{}
+
+// ContextWithReportUser returns a copy of ctx that holds the user for error reports
+func ContextWithReportUser(ctx context.Context, user ReportUser) context.Context {
+	return context.WithValue(ctx, reportUserKey{}, user)
+}
+
+func reportError(r *http.Request, entry ErrorEntry, stackTrace string, panicked bool) {
+	if Config.ErrorReporter == nil || entry.StatusCode < Config.ReportMinStatusCode {
+		return
+	}
+
+	// there is nothing to report when RespondError is given a nil error
+	if entry.Err == nil {
+		return
+	}
+
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+
+	if stackTrace == "" {
+		stackTrace = errorStackTrace(entry.Err)
+	}
+
+	user, _ := ctx.Value(reportUserKey{}).(ReportUser)
+
+	Config.ErrorReporter.Report(ctx, ErrorReport{
+		Err:        errors.CastToHapiError(entry.Err),
+		StatusCode: entry.StatusCode,
+		StackTrace: stackTrace,
+		Panic:      panicked,
+		Method:     entry.Method,
+		Path:       entry.Path,
+		RequestID:  entry.RequestID,
+		User:       user,
+	})
+}
+
+type stackTracer interface {
+	StackTrace() goerrors.StackTrace
+}
+
+// errorStackTrace finds the deepest stack trace in the chain since that is closest
+// to where the error actually happened
+func errorStackTrace(err error) string {
+	var stackTrace string
+	for err != nil {
+		tracer, ok := err.(stackTracer)
+		if ok {
+			stackTrace = fmt.Sprintf("%+v", tracer.StackTrace())
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	return stackTrace
+}
+
+// NewSamplingReporter wraps reporter so that only a sampleRate (0 to 1) of the reports get sent, and
+// reports with the same status code and error are only sent once per dedupWindow. A dedupWindow of 0
+// turns off deduplication.
+func NewSamplingReporter(reporter ErrorReporter, sampleRate float64, dedupWindow time.Duration) ErrorReporter {
+	return &samplingReporter{
+		reporter:    reporter,
+		sampleRate:  sampleRate,
+		dedupWindow: dedupWindow,
+		lastSeen:    make(map[string]time.Time),
+		now:         time.Now,
+	}
+}
+
+type samplingReporter struct {
+	reporter    ErrorReporter
+	sampleRate  float64
+	dedupWindow time.Duration
+
+	mu       sync.Mutex
+	lastSeen map[string]time.Time
+	now      func() time.Time
+}
+
+func (s *samplingReporter) Report(ctx context.Context, report ErrorReport) {
+	if s.sampleRate < 1 && rand.Float64() >= s.sampleRate {
+		return
+	}
+
+	if s.dedupWindow > 0 && s.seenRecently(fmt.Sprintf("%d:%s", report.StatusCode, report.Err.Error())) {
+		return
+	}
+
+	s.reporter.Report(ctx, report)
+}
+
+func (s *samplingReporter) seenRecently(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	lastSeen, ok := s.lastSeen[key]
+	if ok && now.Sub(lastSeen) < s.dedupWindow {
+		return true
+	}
+
+	s.lastSeen[key] = now
+
+	// don't let the map grow forever
+	for k, t := range s.lastSeen {
+		if now.Sub(t) >= s.dedupWindow {
+			delete(s.lastSeen, k)
+		}
+	}
+
+	return false
+}
+
+// MemoryReporter is an ErrorReporter that keeps every report in memory, it is useful for tests
+type MemoryReporter struct {
+	mu      sync.Mutex
+	reports []ErrorReport
+}
+
+// Report adds the report to memory
+func (m *MemoryReporter) Report(ctx context.Context, report ErrorReport) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reports = append(m.reports, report)
+}
+
+// Reports gets all the reports that have been reported
+func (m *MemoryReporter) Reports() []ErrorReport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reports := make([]ErrorReport, len(m.reports))
+	copy(reports, m.reports)
+
+	return reports
+}
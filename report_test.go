@@ -0,0 +1,211 @@
+package hapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	goerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+func TestRespondErrorReports(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	testCases := []struct {
+		desc            string
+		err             error
+		minStatusCode   int
+		expectedReports int
+	}{
+		{
+			desc:            "report 5xx",
+			err:             errors.InternalServerError.Wrap(goerrors.New("connection refused"), "failed to get user"),
+			minStatusCode:   http.StatusInternalServerError,
+			expectedReports: 1,
+		},
+		{
+			desc:            "don't report 4xx by default",
+			err:             errors.NotFound.New("could not find user"),
+			minStatusCode:   http.StatusInternalServerError,
+			expectedReports: 0,
+		},
+		{
+			desc:            "report 4xx when configured",
+			err:             errors.NotFound.New("could not find user"),
+			minStatusCode:   http.StatusBadRequest,
+			expectedReports: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			reporter := &MemoryReporter{}
+			Config.ErrorReporter = reporter
+			Config.ReportMinStatusCode = tc.minStatusCode
+
+			req, err := http.NewRequest(http.MethodPost, "/users", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req = req.WithContext(ContextWithReportUser(req.Context(), ReportUser{ID: "user-1"}))
+
+			err = RespondError(httptest.NewRecorder(), tc.err, WithRequest(req))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reports := reporter.Reports()
+			if !assert.Len(t, reports, tc.expectedReports) || tc.expectedReports == 0 {
+				return
+			}
+
+			report := reports[0]
+			assert.Equal(t, errors.CastToHapiError(tc.err), report.Err)
+			assert.Equal(t, http.MethodPost, report.Method)
+			assert.Equal(t, "/users", report.Path)
+			assert.Equal(t, ReportUser{ID: "user-1"}, report.User)
+			assert.False(t, report.Panic)
+			assert.Contains(t, report.StackTrace, "report_test.go")
+		})
+	}
+}
+
+func TestRespondErrorReportsNil(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	memoryReporter := &MemoryReporter{}
+	Config.ErrorReporter = NewSamplingReporter(memoryReporter, 1, time.Minute)
+
+	recorder := httptest.NewRecorder()
+
+	assert.NotPanics(t, func() {
+		_ = RespondError(recorder, nil)
+	})
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Empty(t, memoryReporter.Reports())
+}
+
+func TestRecover(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	reporter := &MemoryReporter{}
+	Config.ErrorReporter = reporter
+
+	handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("oh no")
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.JSONEq(t, `{"error":"`+Config.DefaultErrorMessage+`"}`, recorder.Body.String())
+
+	reports := reporter.Reports()
+	if assert.Len(t, reports, 1) {
+		assert.True(t, reports[0].Panic)
+		assert.EqualError(t, reports[0].Err, "panic: oh no")
+		assert.Contains(t, reports[0].StackTrace, "report_test.go")
+	}
+}
+
+func TestRecoverAfterWrite(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	reporter := &MemoryReporter{}
+	Config.ErrorReporter = reporter
+
+	handler := Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"partial":`))
+		panic("oh no")
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(recorder, req)
+	})
+	assert.Equal(t, `{"partial":`, recorder.Body.String())
+
+	reports := reporter.Reports()
+	if assert.Len(t, reports, 1) {
+		assert.True(t, reports[0].Panic)
+		assert.EqualError(t, reports[0].Err, "panic: oh no")
+	}
+}
+
+func TestSamplingReporter(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		sampleRate      float64
+		dedupWindow     time.Duration
+		errs            []error
+		advance         time.Duration
+		expectedReports int
+	}{
+		{
+			desc:            "sample rate of 0 reports nothing",
+			sampleRate:      0,
+			errs:            []error{errors.InternalServerError.New("one"), errors.InternalServerError.New("two")},
+			expectedReports: 0,
+		},
+		{
+			desc:            "no dedup window reports everything",
+			sampleRate:      1,
+			errs:            []error{errors.InternalServerError.New("one"), errors.InternalServerError.New("one")},
+			expectedReports: 2,
+		},
+		{
+			desc:            "dedup same errors in window",
+			sampleRate:      1,
+			dedupWindow:     time.Minute,
+			errs:            []error{errors.InternalServerError.New("one"), errors.InternalServerError.New("one"), errors.InternalServerError.New("two")},
+			expectedReports: 2,
+		},
+		{
+			desc:            "report same error again after window",
+			sampleRate:      1,
+			dedupWindow:     time.Minute,
+			errs:            []error{errors.InternalServerError.New("one"), errors.InternalServerError.New("one")},
+			advance:         2 * time.Minute,
+			expectedReports: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			memoryReporter := &MemoryReporter{}
+			reporter := NewSamplingReporter(memoryReporter, tc.sampleRate, tc.dedupWindow).(*samplingReporter)
+
+			now := time.Now()
+			reporter.now = func() time.Time { return now }
+
+			for _, err := range tc.errs {
+				reporter.Report(context.Background(), ErrorReport{
+					Err:        errors.CastToHapiError(err),
+					StatusCode: http.StatusInternalServerError,
+				})
+
+				now = now.Add(tc.advance)
+			}
+
+			assert.Len(t, memoryReporter.Reports(), tc.expectedReports)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package hapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header the request id is read from and echoed back in
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength stops clients from sending us huge ids that we would then
+// echo back and log
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestID is middleware that reads the X-Request-ID header from the request, or generates
+// a new id if there isn't one, stores it in the request context and echoes it in the response
+// header. RespondError will include the id in the error body when it is available.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+
+		next.ServeHTTP(w, r.WithContext(ContextWithRequestID(r.Context(), requestID)))
+	})
+}
+
+// ContextWithRequestID returns a copy of ctx that holds the request id
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext will return the request id stored in ctx and true. If there
+// is no request id, it will return an empty string and false
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey{}).(string)
+	if !ok || requestID == "" {
+		return "", false
+	}
+
+	return requestID, true
+}
+
+// responseRequestID gets the id for the error body and the logs so they always match. It is the one in
+// the request's context if the request was given, otherwise the one the RequestID middleware echoed.
+func responseRequestID(w http.ResponseWriter, r *http.Request) string {
+	if r != nil {
+		requestID, ok := RequestIDFromContext(r.Context())
+		if ok {
+			return requestID
+		}
+	}
+
+	return w.Header().Get(RequestIDHeader)
+}
+
+func newRequestID() string {
+	bytes := make([]byte, 16)
+
+	// crypto/rand never returns an error on the platforms we care about
+	_, _ = rand.Read(bytes)
+
+	return hex.EncodeToString(bytes)
+}
@@ -0,0 +1,120 @@
+package hapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thestephenstanton/hapi/errors"
+)
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		incomingRequestID string
+		expectGenerated   bool
+	}{
+		{
+			desc:              "use incoming request id",
+			incomingRequestID: "abc-123",
+		},
+		{
+			desc:            "generate request id when missing",
+			expectGenerated: true,
+		},
+		{
+			desc:              "generate request id when incoming is too long",
+			incomingRequestID: strings.Repeat("a", maxRequestIDLength+1),
+			expectGenerated:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var contextRequestID string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contextRequestID, _ = RequestIDFromContext(r.Context())
+			}))
+
+			req, err := http.NewRequest("GET", "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.incomingRequestID != "" {
+				req.Header.Set(RequestIDHeader, tc.incomingRequestID)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			headerRequestID := recorder.Header().Get(RequestIDHeader)
+
+			assert.Equal(t, contextRequestID, headerRequestID)
+			if tc.expectGenerated {
+				assert.Len(t, headerRequestID, 32)
+				assert.NotEqual(t, tc.incomingRequestID, headerRequestID)
+			} else {
+				assert.Equal(t, tc.incomingRequestID, headerRequestID)
+			}
+		})
+	}
+}
+
+func TestRequestIDFromContextMissing(t *testing.T) {
+	req, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestID, ok := RequestIDFromContext(req.Context())
+
+	assert.False(t, ok)
+	assert.Equal(t, "", requestID)
+}
+
+func TestRespondErrorWithRequestID(t *testing.T) {
+	handler := RequestID(http.HandlerFunc(respondErrorHandler(errors.NotFound.New("could not find the thing"))))
+
+	req, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(RequestIDHeader, "abc-123")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.JSONEq(t, `{"error":"could not find the thing","requestId":"abc-123"}`, recorder.Body.String())
+}
+
+func TestRespondErrorWithContextRequestID(t *testing.T) {
+	req, err := http.NewRequest("GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req = req.WithContext(ContextWithRequestID(req.Context(), "from-context"))
+
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	var entries []ErrorEntry
+	Config.ErrorHooks = []ErrorHook{
+		func(ctx context.Context, entry ErrorEntry) {
+			entries = append(entries, entry)
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	err = RespondError(recorder, errors.NotFound.New("could not find the thing"), WithRequest(req))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.JSONEq(t, `{"error":"could not find the thing","requestId":"from-context"}`, recorder.Body.String())
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "from-context", entries[0].RequestID)
+	}
+}
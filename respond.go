@@ -3,6 +3,7 @@ package hapi
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/thestephenstanton/hapi/errors"
 )
@@ -13,21 +14,65 @@ type hapiError interface {
 	GetMessage() string
 }
 
-// Respond will marshal and return the payload to the client with a given status code.
-func Respond(w http.ResponseWriter, statusCode int, payload interface{}) error {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+type headerError interface {
+	GetHeaders() http.Header
+}
+
+type safeError interface {
+	IsSafe() bool
+}
+
+type messageKeyError interface {
+	GetMessageKey() (string, map[string]interface{})
+}
+
+// Respond will marshal and return the payload to the client with a given status code. If the
+// request was given with WithRequest and the client has already gone away, nothing is written
+// and a ClientClosedRequest error is returned. The same goes for when writing fails because the
+// client closed the connection. Use WithETag, WithBodyETag and WithLastModified to answer
+// conditional requests with 304 Not Modified and WithCacheControl and WithVary for caching. Use
+// WithHeader, WithCookie, WithContentType and WithLocation instead of changing w before responding.
+func Respond(w http.ResponseWriter, statusCode int, payload interface{}, opts ...ResponseOption) error {
+	o := newResponseOptions(opts)
+
+	err := o.clientClosed()
+	if err != nil {
+		return errors.ClientClosedRequest.Wrap(err, "client closed request before responding")
+	}
 
-	if payload == nil && !Config.ReturnNulls {
+	var bytes []byte
+	if payload != nil || Config.ReturnNulls {
+		bytes, err = json.Marshal(payload)
+		if err != nil {
+			return errors.InternalServerError.Wrap(err, "failed to marshal payload")
+		}
+	}
+
+	// everything has to be on the header before the status is written
+	setCacheHeaders(w, o)
+	o.setHeaders(w)
+
+	// the body has to be encoded before the status is written so that it can be hashed for the ETag
+	if respondNotModified(w, o, statusCode, bytes) {
 		return nil
 	}
 
-	bytes, err := json.Marshal(payload)
-	if err != nil {
-		return errors.InternalServerError.Wrap(err, "failed to marshal payload")
+	contentType := o.contentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+
+	if bytes == nil {
+		return nil
 	}
 
 	_, err = w.Write(bytes)
+	if isClientClosedError(err) {
+		return errors.ClientClosedRequest.Wrap(err, "client closed connection while writing bytes")
+	}
 	if err != nil {
 		return errors.InternalServerError.Wrap(err, "failed to write bytes")
 	}
@@ -37,13 +82,130 @@ func Respond(w http.ResponseWriter, statusCode int, payload interface{}) error {
 
 // RespondError will find if the error is a hapiError and if it is, get the message and set it to the error in the response. If err is not a hapiError
 // then the default error message and default
-func RespondError(w http.ResponseWriter, err error) error {
-	return RespondErrorFallback(w, err, Config.DefaultStatusCode)
+func RespondError(w http.ResponseWriter, err error, opts ...ResponseOption) error {
+	return RespondErrorFallback(w, err, Config.DefaultStatusCode, opts...)
 }
 
 // RespondErrorFallback check if err is a type of hapiError. If it isn't, it will fallback
-// to whatever status code you pass in.
-func RespondErrorFallback(w http.ResponseWriter, err error, fallbackStatusCode int) error {
+// to whatever status code you pass in. Every ErrorHook in Config.ErrorHooks is called before
+// responding and the error is sent to Config.ErrorReporter if the status code is high enough,
+// pass WithRequest to give them the request's context, method and path. Errors are sent with
+// Cache-Control: no-store.
+func RespondErrorFallback(w http.ResponseWriter, err error, fallbackStatusCode int, opts ...ResponseOption) error {
+	o := newResponseOptions(opts)
+	err = mapError(err)
+
+	statusCode, message, errorResponse := newErrorResponse(w, o.request, err, fallbackStatusCode)
+
+	// some status codes need headers like WWW-Authenticate or Retry-After, they have to be set before the status is written
+	headerErr, ok := err.(headerError)
+	if ok {
+		for key, values := range headerErr.GetHeaders() {
+			w.Header()[key] = append([]string(nil), values...)
+		}
+	}
+
+	// the suggested backoff could be from an error deeper in the chain
+	if errorResponse.RetryAfter > 0 && w.Header().Get("Retry-After") == "" {
+		w.Header().Set("Retry-After", strconv.Itoa(errorResponse.RetryAfter))
+	}
+
+	// CDNs must never cache an error, WithCacheControl still wins if it was given
+	w.Header().Set("Cache-Control", string(CacheNoStore))
+
+	entry := observeError(w, o, err, statusCode, message)
+	recordErrorType(w, entry.ErrorType)
+
+	return Respond(w, statusCode, errorResponse, opts...)
+}
+
+// newErrorResponse works out the status code, the message and the ErrorResponse for the client from err.
+// The message is returned before it is sanitized so it can still be logged.
+func newErrorResponse(w http.ResponseWriter, r *http.Request, err error, fallbackStatusCode int) (int, string, ErrorResponse) {
+	statusCode, message, translated := errorMessage(w, r, err, fallbackStatusCode)
+
+	errorResponse := NewErrorResponse(sanitizeMessage(err, statusCode, message, translated))
+
+	// every error in a MultiError gets its own entry
+	multiErr, ok := err.(*errors.MultiError)
+	if ok {
+		for _, e := range multiErr.Errs {
+			errStatusCode, errMessage, errTranslated := errorMessage(w, r, e, fallbackStatusCode)
+
+			errorResponse.Errors = append(errorResponse.Errors, ErrorResponse{
+				ErrorMessage: sanitizeMessage(e, errStatusCode, errMessage, errTranslated),
+				ErrorType:    errorTypeName(e),
+				Status:       errStatusCode,
+			})
+		}
+	}
+
+	if errors.IsRetryable(err) {
+		errorResponse.Retryable = true
+
+		retryAfter, ok := errors.RetryAfter(err)
+		if ok {
+			errorResponse.RetryAfter = errors.RetryAfterSeconds(retryAfter)
+		}
+	}
+
+	if Config.ReturnRawError {
+		errorResponse.RawError = err.Error()
+	}
+
+	errorResponse.ErrorType = errorTypeName(err)
+
+	errorResponse.RequestID = responseRequestID(w, r)
+
+	return statusCode, message, errorResponse
+}
+
+// observeError gives err to every ErrorHook and to the ErrorReporter
+func observeError(w http.ResponseWriter, o responseOptions, err error, statusCode int, message string) ErrorEntry {
+	entry := newErrorEntry(w, o.request, err, statusCode, message)
+
+	// the client is gone so whatever went wrong, it isn't a server error we need to hear about
+	if o.clientClosed() != nil {
+		entry.StatusCode = errors.StatusClientClosedRequest
+		entry.ErrorType = errors.ClientClosedRequest
+	}
+
+	runErrorHooks(o.request, entry)
+	reportError(o.request, entry, o.panicStack, o.panicStack != "")
+
+	return entry
+}
+
+// errorTypeOf gets the ErrorType of a HapiError or a MultiError, it is 0 for any other error
+func errorTypeOf(err error) errors.ErrorType {
+	switch e := err.(type) {
+	case errors.HapiError:
+		return e.ErrorType
+	case *errors.MultiError:
+		return e.GetErrorType()
+	default:
+		return 0
+	}
+}
+
+// errorTypeName is the name of err's ErrorType for the response, it is empty unless Config.ReturnErrorType is on
+func errorTypeName(err error) string {
+	if !Config.ReturnErrorType {
+		return ""
+	}
+
+	errorType := errorTypeOf(err)
+	if errorType == 0 {
+		return errors.NoType.String()
+	}
+
+	return errorType.String()
+}
+
+// errorMessage gets the status code and the message for the client from err. If err is not
+// a hapiError, it is the fallback status code with the default error message. It also returns
+// true if the message was translated from Config.Catalog.
+func errorMessage(w http.ResponseWriter, r *http.Request, err error, fallbackStatusCode int) (int, string, bool) {
 	statusCode := fallbackStatusCode
 	message := Config.DefaultErrorMessage
 
@@ -54,56 +216,89 @@ func RespondErrorFallback(w http.ResponseWriter, err error, fallbackStatusCode i
 		message = hapiErr.GetMessage()
 	}
 
+	// translate the message for the client if there is a message key
+	keyErr, ok := err.(messageKeyError)
+	if ok {
+		key, args := keyErr.GetMessageKey()
+
+		translated, lang, ok := translateMessage(r, key, args)
+		if ok {
+			w.Header().Set("Content-Language", lang)
+			return statusCode, translated, true
+		}
+	}
+
 	// if the message is still empty, get the default http status code message
 	if message == "" {
 		message = http.StatusText(statusCode)
 	}
 
-	errorResponse := NewErrorResponse(message)
+	return statusCode, message, false
+}
 
-	if Config.ReturnRawError {
-		errorResponse.RawError = err.Error()
+// sanitizeMessage replaces the message of server errors when Config.SanitizeServerErrors is on.
+// Translated messages are written for the client so they are never replaced.
+func sanitizeMessage(err error, statusCode int, message string, translated bool) string {
+	if !Config.SanitizeServerErrors || statusCode < 500 || translated {
+		return message
+	}
+
+	safeErr, ok := err.(safeError)
+	if ok && safeErr.IsSafe() {
+		return message
+	}
+
+	hapiErr, ok := err.(errors.HapiError)
+	if ok {
+		defaultMessage, ok := Config.DefaultMessages[hapiErr.ErrorType]
+		if ok {
+			return defaultMessage
+		}
+	}
+
+	if Config.DefaultErrorMessage == "" {
+		return http.StatusText(statusCode)
 	}
 
-	return Respond(w, statusCode, errorResponse)
+	return Config.DefaultErrorMessage
 }
 
 // RespondOK will marshal the payload and respond with a 200 status code.
-func RespondOK(w http.ResponseWriter, payload interface{}) error {
-	return Respond(w, http.StatusOK, payload)
+func RespondOK(w http.ResponseWriter, payload interface{}, opts ...ResponseOption) error {
+	return Respond(w, http.StatusOK, payload, opts...)
 }
 
 // RespondBadRequest will marshal the error payload and respond with a 400 status code.
-func RespondBadRequest(w http.ResponseWriter, payload interface{}) error {
-	return Respond(w, http.StatusBadRequest, payload)
+func RespondBadRequest(w http.ResponseWriter, payload interface{}, opts ...ResponseOption) error {
+	return Respond(w, http.StatusBadRequest, payload, opts...)
 }
 
 // RespondUnauthorized will marshal the error payload and respond with a 401 status code.
-func RespondUnauthorized(w http.ResponseWriter, payload interface{}) error {
-	return Respond(w, http.StatusUnauthorized, payload)
+func RespondUnauthorized(w http.ResponseWriter, payload interface{}, opts ...ResponseOption) error {
+	return Respond(w, http.StatusUnauthorized, payload, opts...)
 }
 
 // RespondForbidden will marshal the error payload and respond with a 403 status code.
-func RespondForbidden(w http.ResponseWriter, payload interface{}) error {
-	return Respond(w, http.StatusForbidden, payload)
+func RespondForbidden(w http.ResponseWriter, payload interface{}, opts ...ResponseOption) error {
+	return Respond(w, http.StatusForbidden, payload, opts...)
 }
 
 // RespondNotFound will marshal the error payload and respond with a 404 status code.
-func RespondNotFound(w http.ResponseWriter, payload interface{}) error {
-	return Respond(w, http.StatusNotFound, payload)
+func RespondNotFound(w http.ResponseWriter, payload interface{}, opts ...ResponseOption) error {
+	return Respond(w, http.StatusNotFound, payload, opts...)
 }
 
 // RespondTooLarge will marshal the error payload and respond with a 413 status code.
-func RespondTooLarge(w http.ResponseWriter, payload interface{}) error {
-	return Respond(w, http.StatusRequestEntityTooLarge, payload)
+func RespondTooLarge(w http.ResponseWriter, payload interface{}, opts ...ResponseOption) error {
+	return Respond(w, http.StatusRequestEntityTooLarge, payload, opts...)
 }
 
 // RespondTeapot will marshal the error payload and respond with a 418 status code.
-func RespondTeapot(w http.ResponseWriter, payload interface{}) error {
-	return Respond(w, http.StatusTeapot, payload)
+func RespondTeapot(w http.ResponseWriter, payload interface{}, opts ...ResponseOption) error {
+	return Respond(w, http.StatusTeapot, payload, opts...)
 }
 
 // RespondInternalError will marshal the error payload and respond with a 500 status code.
-func RespondInternalError(w http.ResponseWriter, payload interface{}) error {
-	return Respond(w, http.StatusInternalServerError, payload)
+func RespondInternalError(w http.ResponseWriter, payload interface{}, opts ...ResponseOption) error {
+	return Respond(w, http.StatusInternalServerError, payload, opts...)
 }
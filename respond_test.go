@@ -1,11 +1,18 @@
 package hapi
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"syscall"
 	"testing"
+	"time"
 
 	goerrors "github.com/pkg/errors"
 
@@ -218,9 +225,9 @@ func (c customHapiError) GetMessage() string {
 }
 
 // helps with TestRespondHelpers
-func helperResponderHandlerHelper(helperResponder func(http.ResponseWriter, interface{}) error, payload interface{}) func(http.ResponseWriter, *http.Request) {
+func helperResponderHandlerHelper(helperResponder func(http.ResponseWriter, interface{}, ...ResponseOption) error, payload interface{}, opts ...ResponseOption) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := helperResponder(w, payload)
+		err := helperResponder(w, payload, opts...)
 		if err != nil {
 			panic(err.Error())
 		}
@@ -235,7 +242,7 @@ func TestRespondHelpers(t *testing.T) {
 
 	testCases := []struct {
 		desc               string
-		respond            func(http.ResponseWriter, interface{}) error
+		respond            func(http.ResponseWriter, interface{}, ...ResponseOption) error
 		expectedStatusCode int
 	}{
 		{
@@ -283,7 +290,7 @@ func TestRespondHelpers(t *testing.T) {
 		t.Run(tc.desc, func(t *testing.T) {
 			// We create a ResponseRecorder (which satisfies http.ResponseWriter) to record the response.
 			recorder := httptest.NewRecorder()
-			handler := http.HandlerFunc(helperResponderHandlerHelper(tc.respond, payload))
+			handler := http.HandlerFunc(helperResponderHandlerHelper(tc.respond, payload, WithHeader("X-Helper", "yes")))
 
 			// We don't care about the request, we just care about the response.
 			req, err := http.NewRequest("GET", "/", nil)
@@ -298,6 +305,7 @@ func TestRespondHelpers(t *testing.T) {
 
 			assert.Equal(t, tc.expectedStatusCode, actualStatusCode)
 			assert.Equal(t, expectedBody, actualBody)
+			assert.Equal(t, "yes", recorder.Header().Get("X-Helper"))
 		})
 	}
 }
@@ -380,3 +388,587 @@ func TestChangeConfigDefaults(t *testing.T) {
 		})
 	}
 }
+
+// brokenPipeWriter acts like a client that closed the connection
+type brokenPipeWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (b brokenPipeWriter) Write([]byte) (int, error) {
+	return 0, &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}
+}
+
+func TestRespondClientClosed(t *testing.T) {
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		desc           string
+		w              http.ResponseWriter
+		ctx            context.Context
+		expectedHeader string
+	}{
+		{
+			desc: "client closed request before responding",
+			w:    httptest.NewRecorder(),
+			ctx:  canceledCtx,
+		},
+		{
+			desc:           "client closed connection while writing",
+			w:              brokenPipeWriter{httptest.NewRecorder()},
+			ctx:            context.Background(),
+			expectedHeader: "application/json",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			req, err := http.NewRequestWithContext(tc.ctx, "GET", "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = Respond(tc.w, http.StatusOK, "hello world", WithRequest(req))
+
+			assert.Equal(t, errors.ClientClosedRequest, errors.CastToHapiError(err).ErrorType)
+			assert.Equal(t, tc.expectedHeader, tc.w.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func TestRespondErrorClientClosed(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	reporter := &MemoryReporter{}
+	Config.ErrorReporter = reporter
+
+	var entries []ErrorEntry
+	Config.ErrorHooks = []ErrorHook{
+		func(ctx context.Context, entry ErrorEntry) {
+			entries = append(entries, entry)
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", "/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	err = RespondError(recorder, errors.InternalServerError.New("query failed"), WithRequest(req))
+
+	assert.Equal(t, errors.ClientClosedRequest, errors.CastToHapiError(err).ErrorType)
+	assert.Equal(t, "", recorder.Body.String())
+	assert.Empty(t, reporter.Reports())
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, errors.StatusClientClosedRequest, entries[0].StatusCode)
+		assert.Equal(t, errors.ClientClosedRequest, entries[0].ErrorType)
+	}
+}
+
+func TestRespondErrorHeaders(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		err                error
+		expectedStatusCode int
+		expectedHeaders    map[string]string
+	}{
+		{
+			desc:               "unauthorized with authenticate",
+			err:                errors.Unauthorized.New("who are you").WithAuthenticate(`Bearer realm="api"`),
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedHeaders: map[string]string{
+				"WWW-Authenticate": `Bearer realm="api"`,
+			},
+		},
+		{
+			desc:               "method not allowed with allow",
+			err:                errors.MethodNotAllowed.New("can't do that").WithAllow(http.MethodGet),
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			expectedHeaders: map[string]string{
+				"Allow": http.MethodGet,
+			},
+		},
+		{
+			desc:               "service unavailable with retry after",
+			err:                errors.ServiceUnavailable.New("down for maintenance").WithRetryAfter(time.Minute),
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedHeaders: map[string]string{
+				"Retry-After":  "60",
+				"Content-Type": "application/json",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler := http.HandlerFunc(respondErrorHandler(tc.err))
+
+			req, err := http.NewRequest("GET", "/", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			for key, value := range tc.expectedHeaders {
+				assert.Equal(t, value, recorder.Header().Get(key))
+			}
+		})
+	}
+}
+
+func TestRespondErrorNeverLeaksInternal(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	err := errors.NotFound.Public("user not found").Internal("id=%s shard=%d", "42", 7)
+
+	testCases := []struct {
+		desc           string
+		returnRawError bool
+		expectedBody   string
+	}{
+		{
+			desc:         "raw error off",
+			expectedBody: `{"error":"user not found"}`,
+		},
+		{
+			desc:           "raw error on",
+			returnRawError: true,
+			expectedBody:   `{"error":"user not found","rawError":"id=42 shard=7: user not found"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			Config.ReturnRawError = tc.returnRawError
+
+			recorder := httptest.NewRecorder()
+			respondErr := RespondError(recorder, err)
+			if respondErr != nil {
+				t.Fatal(respondErr)
+			}
+
+			assert.Equal(t, http.StatusNotFound, recorder.Code)
+			assert.JSONEq(t, tc.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestSanitizeServerErrors(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	testCases := []struct {
+		desc            string
+		err             error
+		defaultMessages map[errors.ErrorType]string
+		expectedBody    string
+	}{
+		{
+			desc:         "5xx message is replaced",
+			err:          errors.InternalServerError.Wrap(goerrors.New("connection refused"), "query failed: SELECT * FROM users"),
+			expectedBody: fmt.Sprintf(`{"error":"%s"}`, Config.DefaultErrorMessage),
+		},
+		{
+			desc: "5xx message is replaced with message for the type",
+			err:  errors.ServiceUnavailable.New("redis at 10.0.0.1 is down"),
+			defaultMessages: map[errors.ErrorType]string{
+				errors.ServiceUnavailable: "we are down for maintenance",
+			},
+			expectedBody: `{"error":"we are down for maintenance","retryable":true}`,
+		},
+		{
+			desc:         "public 5xx message is left alone",
+			err:          errors.InternalServerError.Public("we are having trouble, try again soon"),
+			expectedBody: `{"error":"we are having trouble, try again soon"}`,
+		},
+		{
+			desc:         "safe 5xx message is left alone",
+			err:          errors.InternalServerError.New("we are having trouble, try again soon").MarkSafe(),
+			expectedBody: `{"error":"we are having trouble, try again soon"}`,
+		},
+		{
+			desc:         "4xx message is left alone",
+			err:          errors.BadRequest.New("name is required"),
+			expectedBody: `{"error":"name is required"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var entries []ErrorEntry
+			Config.SanitizeServerErrors = true
+			Config.DefaultMessages = tc.defaultMessages
+			Config.ErrorHooks = []ErrorHook{
+				func(ctx context.Context, entry ErrorEntry) {
+					entries = append(entries, entry)
+				},
+			}
+
+			recorder := httptest.NewRecorder()
+			err := RespondError(recorder, tc.err)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.JSONEq(t, tc.expectedBody, recorder.Body.String())
+
+			// hooks still get the original message so it can be logged
+			if assert.Len(t, entries, 1) {
+				assert.Equal(t, errors.CastToHapiError(tc.err).GetMessage(), entries[0].Message)
+			}
+		})
+	}
+}
+
+func TestRespondErrorMultiError(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	testCases := []struct {
+		desc               string
+		err                error
+		precedence         []errors.ErrorType
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			desc: "hapi multi error",
+			err: &errors.MultiError{
+				Errs: []error{
+					errors.BadRequest.New("name is required"),
+					errors.NotFound.New("could not find team"),
+				},
+				Message: "could not create user",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody: `
+			{
+				"error": "could not create user",
+				"errors": [
+					{"error": "name is required", "status": 400},
+					{"error": "could not find team", "status": 404}
+				]
+			}`,
+		},
+		{
+			desc:               "standard library join",
+			err:                stderrors.Join(errors.BadRequest.New("name is required"), sql.ErrNoRows),
+			expectedStatusCode: http.StatusNotFound,
+			expectedBody: `
+			{
+				"error": "Not Found",
+				"errors": [
+					{"error": "name is required", "status": 400},
+					{"error": "Not Found", "status": 404}
+				]
+			}`,
+		},
+		{
+			desc:               "precedence from config",
+			err:                stderrors.Join(errors.NotFound.New("could not find team"), errors.BadRequest.New("name is required")),
+			precedence:         []errors.ErrorType{errors.BadRequest},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody: `
+			{
+				"error": "Bad Request",
+				"errors": [
+					{"error": "could not find team", "status": 404},
+					{"error": "name is required", "status": 400}
+				]
+			}`,
+		},
+		{
+			desc: "precedence of the multi error wins over config",
+			err: &errors.MultiError{
+				Errs:       []error{errors.NotFound.New("could not find team"), errors.Unauthorized.New("who are you")},
+				Precedence: []errors.ErrorType{errors.Unauthorized},
+			},
+			precedence:         []errors.ErrorType{errors.NotFound},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedBody: `
+			{
+				"error": "Unauthorized",
+				"errors": [
+					{"error": "could not find team", "status": 404},
+					{"error": "who are you", "status": 401}
+				]
+			}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			Config.MultiErrorPrecedence = tc.precedence
+
+			recorder := httptest.NewRecorder()
+			err := RespondError(recorder, tc.err)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			assert.JSONEq(t, tc.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestRespondErrorRetryHint(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		err                error
+		expectedStatusCode int
+		expectedBody       string
+		expectedRetryAfter string
+	}{
+		{
+			desc:               "retryable with backoff",
+			err:                errors.TooManyRequests.New("slow down").WithRetryAfter(30 * time.Second),
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedBody:       `{"error":"slow down","retryable":true,"retryAfter":30}`,
+			expectedRetryAfter: "30",
+		},
+		{
+			desc:               "backoff from deeper in the chain",
+			err:                errors.Wrap(errors.InternalServerError.New("deadlock").WithRetry(5*time.Second), "failed to save user"),
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedBody:       `{"error":"failed to save user","retryable":true,"retryAfter":5}`,
+			expectedRetryAfter: "5",
+		},
+		{
+			desc:               "not retryable",
+			err:                errors.BadRequest.New("name is required"),
+			expectedStatusCode: http.StatusBadRequest,
+			expectedBody:       `{"error":"name is required"}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			err := RespondError(recorder, tc.err)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.expectedStatusCode, recorder.Code)
+			assert.JSONEq(t, tc.expectedBody, recorder.Body.String())
+			assert.Equal(t, tc.expectedRetryAfter, recorder.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestRespondErrorUnmarshalled(t *testing.T) {
+	bytes, err := json.Marshal(errors.ServiceUnavailable.Public("down for maintenance").Internal("db migration").WithRetryAfter(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hapiErr errors.HapiError
+	err = json.Unmarshal(bytes, &hapiErr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	Config.SanitizeServerErrors = true
+
+	recorder := httptest.NewRecorder()
+	err = RespondError(recorder, hapiErr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.JSONEq(t, `{"error":"down for maintenance","retryable":true,"retryAfter":60}`, recorder.Body.String())
+	assert.Equal(t, "60", recorder.Header().Get("Retry-After"))
+}
+
+// headerOrderWriter fails the test if a header is changed after the status is written
+type headerOrderWriter struct {
+	*httptest.ResponseRecorder
+	t *testing.T
+
+	headers http.Header
+}
+
+func (h *headerOrderWriter) WriteHeader(statusCode int) {
+	h.headers = h.Header().Clone()
+	h.ResponseRecorder.WriteHeader(statusCode)
+}
+
+func (h *headerOrderWriter) Write(bytes []byte) (int, error) {
+	assert.Equal(h.t, h.headers, h.Header(), "headers changed after the status was written")
+	return h.ResponseRecorder.Write(bytes)
+}
+
+func TestRespondOptions(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		statusCode          int
+		opts                []ResponseOption
+		existingHeaders     http.Header
+		expectedHeaders     http.Header
+		expectedContentType string
+	}{
+		{
+			desc:                "no options",
+			statusCode:          http.StatusOK,
+			expectedHeaders:     http.Header{},
+			expectedContentType: "application/json",
+		},
+		{
+			desc:       "headers",
+			statusCode: http.StatusOK,
+			opts:       []ResponseOption{WithHeader("X-Total-Count", "42"), WithHeader("link", "</users?page=2>"), WithHeader("Link", "</users?page=9>")},
+			expectedHeaders: http.Header{
+				"X-Total-Count": []string{"42"},
+				"Link":          []string{"</users?page=2>", "</users?page=9>"},
+			},
+			expectedContentType: "application/json",
+		},
+		{
+			desc:            "header replaces the one on the writer",
+			statusCode:      http.StatusOK,
+			opts:            []ResponseOption{WithHeader("X-Total-Count", "42")},
+			existingHeaders: http.Header{"X-Total-Count": []string{"41"}},
+			expectedHeaders: http.Header{
+				"X-Total-Count": []string{"42"},
+			},
+			expectedContentType: "application/json",
+		},
+		{
+			desc:       "cookies",
+			statusCode: http.StatusOK,
+			opts: []ResponseOption{
+				WithCookie(&http.Cookie{Name: "session", Value: "abc", HttpOnly: true}),
+				WithCookie(&http.Cookie{Name: "theme", Value: "dark"}),
+			},
+			expectedHeaders: http.Header{
+				"Set-Cookie": []string{"session=abc; HttpOnly", "theme=dark"},
+			},
+			expectedContentType: "application/json",
+		},
+		{
+			desc:                "content type",
+			statusCode:          http.StatusOK,
+			opts:                []ResponseOption{WithContentType("application/vnd.api+json")},
+			expectedHeaders:     http.Header{},
+			expectedContentType: "application/vnd.api+json",
+		},
+		{
+			desc:       "location",
+			statusCode: http.StatusCreated,
+			opts:       []ResponseOption{WithLocation("/users/42")},
+			expectedHeaders: http.Header{
+				"Location": []string{"/users/42"},
+			},
+			expectedContentType: "application/json",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			w := &headerOrderWriter{
+				ResponseRecorder: httptest.NewRecorder(),
+				t:                t,
+			}
+			for key, values := range tc.existingHeaders {
+				w.Header()[key] = values
+			}
+
+			err := Respond(w, tc.statusCode, "hello world", tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tc.statusCode, w.Code)
+			assert.Equal(t, `"hello world"`, w.Body.String())
+			assert.Equal(t, tc.expectedContentType, w.Header().Get("Content-Type"))
+
+			w.Header().Del("Content-Type")
+			assert.Equal(t, tc.expectedHeaders, w.Header())
+		})
+	}
+}
+
+func TestRespondErrorOptions(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	err := RespondError(recorder, errors.NotFound.New("user not found"), WithContentType("application/problem+json"), WithHeader("X-Error", "yes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "yes", recorder.Header().Get("X-Error"))
+	assert.JSONEq(t, `{"error":"user not found"}`, recorder.Body.String())
+}
+
+func TestRespondErrorUnmarshalledWithoutCauses(t *testing.T) {
+	var hapiErr errors.HapiError
+	err := json.Unmarshal([]byte(`{"type":"NotFound","status":404,"message":"user not found"}`), &hapiErr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	Config.ReturnRawError = true
+
+	recorder := httptest.NewRecorder()
+	err = RespondError(recorder, hapiErr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.JSONEq(t, `{"error":"user not found","rawError":"user not found"}`, recorder.Body.String())
+}
+
+func TestRespondErrorReturnErrorType(t *testing.T) {
+	originalConfig := Config
+	defer func() { Config = originalConfig }()
+
+	Config.ReturnErrorType = true
+
+	testCases := []struct {
+		desc         string
+		err          error
+		expectedBody string
+	}{
+		{
+			desc:         "hapi error",
+			err:          errors.NotFound.New("user not found"),
+			expectedBody: `{"error":"user not found","type":"NotFound"}`,
+		},
+		{
+			desc:         "standard error",
+			err:          goerrors.New("some go error"),
+			expectedBody: fmt.Sprintf(`{"error":"%s","type":"NoType"}`, Config.DefaultErrorMessage),
+		},
+		{
+			desc: "multi error",
+			err:  errors.Join(errors.BadRequest.New("name is required"), errors.NotFound.New("team not found")),
+			expectedBody: `{"error":"Not Found","type":"NotFound","errors":[
+				{"error":"name is required","type":"BadRequest","status":400},
+				{"error":"team not found","type":"NotFound","status":404}
+			]}`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			err := RespondError(recorder, tc.err)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.JSONEq(t, tc.expectedBody, recorder.Body.String())
+		})
+	}
+}